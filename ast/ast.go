@@ -3,6 +3,9 @@ package ast
 import (
 	"fmt"
 	"github.com/Lebonesco/go-compiler/token"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 // interface methods
@@ -25,12 +28,51 @@ func (is IfStatement) TokenLiteral() string { return "IfStatement" }
 func (bs BlockStatement) statementNode()       {}
 func (bs BlockStatement) TokenLiteral() string { return "BlockStatement" }
 
+func (fs ForStatement) statementNode()       {}
+func (fs ForStatement) TokenLiteral() string { return "ForStatement" }
+
+func (fs ForRangeStatement) statementNode()       {}
+func (fs ForRangeStatement) TokenLiteral() string { return "ForRangeStatement" }
+
+func (bs BreakStatement) statementNode()       {}
+func (bs BreakStatement) TokenLiteral() string { return "BreakStatement" }
+
+func (cs ContinueStatement) statementNode()       {}
+func (cs ContinueStatement) TokenLiteral() string { return "ContinueStatement" }
+
+func (fs ForInStatement) statementNode()       {}
+func (fs ForInStatement) TokenLiteral() string { return "ForInStatement" }
+
 func (is InitStatement) statementNode()       {}
 func (is InitStatement) TokenLiteral() string { return "InitStatement" }
 
+func (ts TupleInitStatement) statementNode()       {}
+func (ts TupleInitStatement) TokenLiteral() string { return "TupleInitStatement" }
+
+func (as IndexAssignStatement) statementNode()       {}
+func (as IndexAssignStatement) TokenLiteral() string { return "IndexAssignStatement" }
+
 func (fs FunctionStatement) statementNode()       {}
 func (fs FunctionStatement) TokenLiteral() string { return "FunctionStatement" }
 
+func (ss StructStatement) statementNode()       {}
+func (ss StructStatement) TokenLiteral() string { return "StructStatement" }
+
+func (ts TypeStatement) statementNode()       {}
+func (ts TypeStatement) TokenLiteral() string { return "TypeStatement" }
+
+func (is InterfaceStatement) statementNode()       {}
+func (is InterfaceStatement) TokenLiteral() string { return "InterfaceStatement" }
+
+func (es EnumStatement) statementNode()       {}
+func (es EnumStatement) TokenLiteral() string { return "EnumStatement" }
+
+func (ms MatchStatement) statementNode()       {}
+func (ms MatchStatement) TokenLiteral() string { return "MatchStatement" }
+
+func (as FieldAssignStatement) statementNode()       {}
+func (as FieldAssignStatement) TokenLiteral() string { return "FieldAssignStatement" }
+
 // Expressions
 func (i Identifier) expressionNode()      {}
 func (i Identifier) TokenLiteral() string { return string(i.Token.Lit) }
@@ -44,12 +86,48 @@ func (b Boolean) TokenLiteral() string { return string(b.Token.Lit) }
 func (il IntegerLiteral) expressionNode()      {}
 func (il IntegerLiteral) TokenLiteral() string { return string(il.Token.Lit) }
 
+func (fl FloatLiteral) expressionNode()      {}
+func (fl FloatLiteral) TokenLiteral() string { return string(fl.Token.Lit) }
+
 func (oe InfixExpression) expressionNode()      {}
 func (oe InfixExpression) TokenLiteral() string { return string(oe.Token.Lit) }
 
+func (pe PrefixExpression) expressionNode()      {}
+func (pe PrefixExpression) TokenLiteral() string { return string(pe.Token.Lit) }
+
+func (ll ListLiteral) expressionNode()      {}
+func (ll ListLiteral) TokenLiteral() string { return string(ll.Token.Lit) }
+
+func (ve VariantExpression) expressionNode()      {}
+func (ve VariantExpression) TokenLiteral() string { return string(ve.Token.Lit) }
+
+func (me MatchExpression) expressionNode()      {}
+func (me MatchExpression) TokenLiteral() string { return string(me.Token.Lit) }
+
+func (fe FieldExpression) expressionNode()      {}
+func (fe FieldExpression) TokenLiteral() string { return string(fe.Token.Lit) }
+
+func (nl NoneLiteral) expressionNode()      {}
+func (nl NoneLiteral) TokenLiteral() string { return string(nl.Token.Lit) }
+
+func (tl TupleLiteral) expressionNode()      {}
+func (tl TupleLiteral) TokenLiteral() string { return string(tl.Token.Lit) }
+
+func (ml MapLiteral) expressionNode()      {}
+func (ml MapLiteral) TokenLiteral() string { return string(ml.Token.Lit) }
+
+func (ie IndexExpression) expressionNode()      {}
+func (ie IndexExpression) TokenLiteral() string { return string(ie.Token.Lit) }
+
 func (fc FunctionCall) expressionNode()      {}
 func (fc FunctionCall) TokenLiteral() string { return string(fc.Token.Lit) }
 
+func (fl FunctionLiteral) expressionNode()      {}
+func (fl FunctionLiteral) TokenLiteral() string { return string(fl.Token.Lit) }
+
+func (ce CallExpression) expressionNode()      {}
+func (ce CallExpression) TokenLiteral() string { return string(ce.Token.Lit) }
+
 func Error(fun, expected, v string, got interface{}) error {
 	return fmt.Errorf("AST construction error: In function: %s, expected %s for %s. got=%T", fun, expected, v, got)
 }
@@ -113,165 +191,1145 @@ func NewBlockStatement(stmts Attrib) (*BlockStatement, error) {
 	return &BlockStatement{Statements: s}, nil
 }
 
-func NewFunctionStatement(name, args, ret, block Attrib) (Statement, error) {
+func NewIndexAssignStatement(left, tok, index, right Attrib) (Statement, error) {
+	l, err := NewIndexExpression(left, tok, index)
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := right.(Expression)
+	if !ok {
+		return nil, Error("NewIndexAssignStatement", "Expression", "right", right)
+	}
+
+	return &IndexAssignStatement{Token: l.Token, Left: l, Right: r}, nil
+}
+
+func NewFieldAssignStatement(left, tok, field, right Attrib) (Statement, error) {
+	l, err := NewFieldExpression(left, tok, field)
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := right.(Expression)
+	if !ok {
+		return nil, Error("NewFieldAssignStatement", "Expression", "right", right)
+	}
+
+	return &FieldAssignStatement{Token: l.Token, Left: l, Right: r}, nil
+}
+
+func NewStructStatement(name, fields Attrib) (Statement, error) {
 	n, ok := name.(*token.Token)
 	if !ok {
-		return nil, Error("NewFunctionStatement", "*token.Token", "name", name)
+		return nil, Error("NewStructStatement", "*token.Token", "name", name)
 	}
 
-	b, ok := block.(*BlockStatement)
+	f := []FormalArg{}
+	if fields != nil {
+		f, ok = fields.([]FormalArg)
+		if !ok {
+			return nil, Error("NewStructStatement", "[]FormalArg", "fields", fields)
+		}
+	}
+
+	return &StructStatement{Token: n, Name: string(n.Lit), Fields: f}, nil
+}
+
+func NewTypeStatement(name, kind Attrib, alias bool) (Statement, error) {
+	n, ok := name.(*token.Token)
 	if !ok {
-		return nil, Error("NewFunctionStatement", "BlockStatement", "block", block)
+		return nil, Error("NewTypeStatement", "*token.Token", "name", name)
+	}
+
+	k, ok := kind.(string)
+	if !ok {
+		return nil, Error("NewTypeStatement", "string", "kind", kind)
+	}
+
+	return &TypeStatement{Token: n, Name: string(n.Lit), Type: k, Alias: alias}, nil
+}
+
+func NewInterfaceStatement(name, methods Attrib) (Statement, error) {
+	n, ok := name.(*token.Token)
+	if !ok {
+		return nil, Error("NewInterfaceStatement", "*token.Token", "name", name)
+	}
+
+	ms := []MethodSpec{}
+	if methods != nil {
+		ms, ok = methods.([]MethodSpec)
+		if !ok {
+			return nil, Error("NewInterfaceStatement", "[]MethodSpec", "methods", methods)
+		}
+	}
+
+	return &InterfaceStatement{Token: n, Name: string(n.Lit), Methods: ms}, nil
+}
+
+func NewMethodSpec(name, args, ret Attrib) (MethodSpec, error) {
+	n, ok := name.(*token.Token)
+	if !ok {
+		return MethodSpec{}, Error("NewMethodSpec", "*token.Token", "name", name)
 	}
 
 	a := []FormalArg{}
 	if args != nil {
 		a, ok = args.([]FormalArg)
 		if !ok {
-			return nil, Error("NewFunctionStatement", "[]FormalArg", "args", args)
+			return MethodSpec{}, Error("NewMethodSpec", "[]FormalArg", "args", args)
 		}
 	}
 
-	r, ok := ret.(*token.Token)
+	r, ok := ret.(string)
 	if !ok {
-		// bad
+		return MethodSpec{}, Error("NewMethodSpec", "string", "ret", ret)
 	}
 
-	return &FunctionStatement{Name: string(n.Lit), Body: b, Parameters: a, Return: string(r.Lit)}, nil
+	return MethodSpec{Name: string(n.Lit), Parameters: a, Return: r}, nil
 }
 
-func NewIfStatement(cond, cons, alt Attrib) (Statement, error) {
-	c, ok := cond.(Expression)
+func NewMethodSpecList(spec Attrib) ([]MethodSpec, error) {
+	return AppendMethodSpec([]MethodSpec{}, spec)
+}
+
+func AppendMethodSpec(specs, spec Attrib) ([]MethodSpec, error) {
+	ss, ok := specs.([]MethodSpec)
 	if !ok {
-		return nil, fmt.Errorf("invalid type of cond. got=%T", cond)
+		return nil, Error("AppendMethodSpec", "[]MethodSpec", "specs", specs)
 	}
 
-	cs, ok := cons.(*BlockStatement)
+	s, ok := spec.(MethodSpec)
 	if !ok {
-		return nil, fmt.Errorf("invalid type of cons. got=%T", cons)
+		return nil, Error("AppendMethodSpec", "MethodSpec", "spec", spec)
+	}
+
+	return append(ss, s), nil
+}
+
+func NewEnumStatement(name, variants Attrib) (Statement, error) {
+	n, ok := name.(*token.Token)
+	if !ok {
+		return nil, Error("NewEnumStatement", "*token.Token", "name", name)
 	}
 
-	a, ok := alt.(*BlockStatement)
+	vs, ok := variants.([]Variant)
 	if !ok {
-		return nil, fmt.Errorf("invalid type of alt. got=%T", alt)
+		return nil, Error("NewEnumStatement", "[]Variant", "variants", variants)
 	}
 
-	return &IfStatement{Condition: c, Block: cs, Alternative: a}, nil
+	return &EnumStatement{Token: n, Name: string(n.Lit), Variants: vs}, nil
 }
 
-func NewInfixExpression(left, right, oper Attrib) (Expression, error) {
-	l, ok := left.(Expression)
+func NewVariant(name, types Attrib) (Variant, error) {
+	n, ok := name.(*token.Token)
 	if !ok {
-		fmt.Println(left)
-		return nil, Error("NewInfixExpression", "Expression", "left", left)
+		return Variant{}, Error("NewVariant", "*token.Token", "name", name)
 	}
 
-	o, ok := oper.(*token.Token)
+	ts := []string{}
+	if types != nil {
+		ts, ok = types.([]string)
+		if !ok {
+			return Variant{}, Error("NewVariant", "[]string", "types", types)
+		}
+	}
+
+	return Variant{Name: string(n.Lit), Types: ts}, nil
+}
+
+func NewVariantList(variant Attrib) ([]Variant, error) {
+	return AppendVariant([]Variant{}, variant)
+}
+
+func AppendVariant(variants, variant Attrib) ([]Variant, error) {
+	vs, ok := variants.([]Variant)
 	if !ok {
-		return nil, Error("NewInfixExpression", "*token.Token", "oper", oper)
+		return nil, Error("AppendVariant", "[]Variant", "variants", variants)
 	}
 
-	r, ok := right.(Expression)
+	v, ok := variant.(Variant)
 	if !ok {
-		return nil, Error("NewInfixExpression", "Expression", "right", right)
+		return nil, Error("AppendVariant", "Variant", "variant", variant)
 	}
 
-	return &InfixExpression{Left: l, Operator: string(o.Lit), Right: r, Token: o}, nil
+	return append(vs, v), nil
 }
 
-func NewIntegerLiteral(integer Attrib) (Expression, error) {
-	intLit, ok := integer.(*token.Token)
+func NewMatchStatement(tok, value, arms Attrib) (Statement, error) {
+	t, ok := tok.(*token.Token)
 	if !ok {
-		return nil, Error("NewIntegerLiteral", "*token.Token", "integer", integer)
+		return nil, Error("NewMatchStatement", "*token.Token", "tok", tok)
 	}
 
-	return &IntegerLiteral{Token: intLit, Value: string(intLit.Lit)}, nil
+	v, ok := value.(Expression)
+	if !ok {
+		return nil, Error("NewMatchStatement", "Expression", "value", value)
+	}
+
+	as, ok := arms.([]MatchArm)
+	if !ok {
+		return nil, Error("NewMatchStatement", "[]MatchArm", "arms", arms)
+	}
+
+	return &MatchStatement{Token: t, Value: v, Arms: as}, nil
 }
 
-func NewStringLiteral(str Attrib) (Expression, error) {
-	return &StringLiteral{Value: string(str.(*token.Token).Lit), Token: str.(*token.Token)}, nil
+func NewMatchArmList(pattern, body Attrib) ([]MatchArm, error) {
+	return AppendMatchArm([]MatchArm{}, pattern, body)
 }
 
-func NewIdentInit(ident, expr Attrib) (Statement, error) {
-	e, ok := expr.(Expression)
+// body is a block for match statements and an expression for match expressions
+func AppendMatchArm(arms, pattern, body Attrib) ([]MatchArm, error) {
+	as, ok := arms.([]MatchArm)
 	if !ok {
-		return nil, Error("NewIdentInit", "Expression", "expr", expr)
+		return nil, Error("AppendMatchArm", "[]MatchArm", "arms", arms)
 	}
 
-	return &InitStatement{Location: string(ident.(*token.Token).Lit), Token: ident.(*token.Token), Expr: e}, nil
+	p, ok := pattern.(Pattern)
+	if !ok {
+		return nil, Error("AppendMatchArm", "Pattern", "pattern", pattern)
+	}
+
+	switch b := body.(type) {
+	case *BlockStatement:
+		return append(as, MatchArm{Pattern: p, Block: b}), nil
+	case Expression:
+		return append(as, MatchArm{Pattern: p, Value: b}), nil
+	}
+	return nil, Error("AppendMatchArm", "*BlockStatement or Expression", "body", body)
 }
 
-func NewIdentExpression(ident Attrib) (*Identifier, error) {
-	return &Identifier{Value: string(ident.(*token.Token).Lit), Token: ident.(*token.Token)}, nil
+func NewPattern(enum, variant, bindings Attrib) (Pattern, error) {
+	e, ok := enum.(*token.Token)
+	if !ok {
+		return Pattern{}, Error("NewPattern", "*token.Token", "enum", enum)
+	}
+
+	v, ok := variant.(*token.Token)
+	if !ok {
+		return Pattern{}, Error("NewPattern", "*token.Token", "variant", variant)
+	}
+
+	bs := []string{}
+	if bindings != nil {
+		bs, ok = bindings.([]string)
+		if !ok {
+			return Pattern{}, Error("NewPattern", "[]string", "bindings", bindings)
+		}
+	}
+
+	return Pattern{Token: e, Enum: string(e.Lit), Variant: string(v.Lit), Bindings: bs}, nil
 }
 
-func NewBoolExpression(val Attrib) (Expression, error) {
-	return &Boolean{Value: val.(bool)}, nil
+func NewWildcardPattern(tok Attrib) (Pattern, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return Pattern{}, Error("NewWildcardPattern", "*token.Token", "tok", tok)
+	}
+
+	if string(t.Lit) != "_" {
+		return Pattern{}, fmt.Errorf("invalid pattern %s, expected Enum::Variant or _", t.Lit)
+	}
+
+	return Pattern{Token: t, Variant: "_", Bindings: []string{}}, nil
 }
 
-func NewReturnStatement(exp Attrib) (Statement, error) {
-	e, ok := exp.(Expression)
+func NewIdentList(ident Attrib) ([]string, error) {
+	return AppendIdent([]string{}, ident)
+}
+
+func AppendIdent(idents, ident Attrib) ([]string, error) {
+	is, ok := idents.([]string)
 	if !ok {
-		return nil, Error("NewReturnExpression", "Expression", "exp", exp)
+		return nil, Error("AppendIdent", "[]string", "idents", idents)
 	}
-	return &ReturnStatement{ReturnValue: e}, nil
+
+	i, ok := ident.(*token.Token)
+	if !ok {
+		return nil, Error("AppendIdent", "*token.Token", "ident", ident)
+	}
+
+	return append(is, string(i.Lit)), nil
 }
 
-func NewFunctionCall(name, args Attrib) (Expression, error) {
+func NewFunctionStatement(name, args, ret, block Attrib) (Statement, error) {
 	n, ok := name.(*token.Token)
 	if !ok {
-		return nil, fmt.Errorf("invalid type of name. got=%T", name)
+		return nil, Error("NewFunctionStatement", "*token.Token", "name", name)
 	}
 
-	a := []Expression{}
+	b, ok := block.(*BlockStatement)
+	if !ok {
+		return nil, Error("NewFunctionStatement", "BlockStatement", "block", block)
+	}
+
+	a := []FormalArg{}
 	if args != nil {
-		var ok bool
-		a, ok = args.([]Expression)
+		a, ok = args.([]FormalArg)
 		if !ok {
-			return nil, Error("NewFunctionCall", "[]Expression", "args", args)
+			return nil, Error("NewFunctionStatement", "[]FormalArg", "args", args)
 		}
 	}
 
-	return &FunctionCall{Name: string(n.Lit), Args: a, Token: n}, nil
+	r := "Nothing" // procedures leave out the return type
+	if ret != nil {
+		r, ok = ret.(string)
+		if !ok {
+			return nil, Error("NewFunctionStatement", "string", "ret", ret)
+		}
+	}
+
+	return &FunctionStatement{Name: string(n.Lit), Body: b, Parameters: a, Return: r}, nil
 }
 
-func NewFormalArg() ([]FormalArg, error) {
-	return []FormalArg{}, nil
+func NewGenericFunctionStatement(name, params, args, ret, block Attrib) (Statement, error) {
+	f, err := NewFunctionStatement(name, args, ret, block)
+	if err != nil {
+		return nil, err
+	}
+
+	ps, ok := params.([]TypeParam)
+	if !ok {
+		return nil, Error("NewGenericFunctionStatement", "[]TypeParam", "params", params)
+	}
+
+	fs := f.(*FunctionStatement)
+	fs.TypeParams = ps
+	return fs, nil
 }
 
-func AppendFormalArgs(args, arg, kind Attrib) ([]FormalArg, error) {
-	as, ok := args.([]FormalArg)
+func NewTypeParam(name, constraint Attrib) (TypeParam, error) {
+	n, ok := name.(*token.Token)
 	if !ok {
-		return nil, Error("AppendFormalArgs", "[]FormalArg", "args", args)
+		return TypeParam{}, Error("NewTypeParam", "*token.Token", "name", name)
 	}
 
-	a, ok := arg.(*token.Token)
+	c := ""
+	if constraint != nil {
+		t, ok := constraint.(*token.Token)
+		if !ok {
+			return TypeParam{}, Error("NewTypeParam", "*token.Token", "constraint", constraint)
+		}
+		c = string(t.Lit)
+	}
+
+	return TypeParam{Name: string(n.Lit), Constraint: c}, nil
+}
+
+func NewTypeParamList(param Attrib) ([]TypeParam, error) {
+	return AppendTypeParam([]TypeParam{}, param)
+}
+
+func AppendTypeParam(params, param Attrib) ([]TypeParam, error) {
+	ps, ok := params.([]TypeParam)
 	if !ok {
-		return nil, Error("AppendFormalArgs", "*token.Token", "arg", arg)
+		return nil, Error("AppendTypeParam", "[]TypeParam", "params", params)
 	}
 
-	k, ok := kind.(*token.Token)
+	p, ok := param.(TypeParam)
 	if !ok {
-		return nil, fmt.Errorf("invalid type of kind. got=%T", kind)
+		return nil, Error("AppendTypeParam", "TypeParam", "param", param)
 	}
 
-	return append(as, FormalArg{string(a.Lit), string(k.Lit)}), nil
+	return append(ps, p), nil
 }
 
-func NewArg() ([]Expression, error) {
-	return []Expression{}, nil
+func NewMethodStatement(recv, kind, name, args, ret, block Attrib) (Statement, error) {
+	f, err := NewFunctionStatement(name, args, ret, block)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := AppendFormalArgs([]FormalArg{}, recv, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	fs := f.(*FunctionStatement)
+	fs.Receiver = &r[0]
+	return fs, nil
 }
 
-func AppendArgs(expr, args Attrib) ([]Expression, error) {
-	as, ok := args.([]Expression)
+func NewIfStatement(cond, cons, alt Attrib) (Statement, error) {
+	c, ok := cond.(Expression)
 	if !ok {
-		return nil, Error("AppendFormalArgs", "[]FormalArgs", "args", args)
+		return nil, fmt.Errorf("invalid type of cond. got=%T", cond)
 	}
 
-	e, ok := expr.(Expression)
+	cs, ok := cons.(*BlockStatement)
 	if !ok {
-		return nil, Error("AppendFormalArgs", "Expression", "expr", expr)
+		return nil, fmt.Errorf("invalid type of cons. got=%T", cons)
 	}
 
-	return append(as, e), nil
+	var a *BlockStatement
+	if alt != nil { // else is optional
+		a, ok = alt.(*BlockStatement)
+		if !ok {
+			return nil, fmt.Errorf("invalid type of alt. got=%T", alt)
+		}
+	}
+
+	return &IfStatement{Condition: c, Block: cs, Alternative: a}, nil
+}
+
+// else if is an else block holding a single if statement
+func NewElseIfBlock(cond, cons, alt Attrib) (*BlockStatement, error) {
+	s, err := NewIfStatement(cond, cons, alt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockStatement{Statements: []Statement{s}}, nil
+}
+
+func NewIfLetStatement(ident, value, cons, alt Attrib) (Statement, error) {
+	i, ok := ident.(*token.Token)
+	if !ok {
+		return nil, Error("NewIfLetStatement", "*token.Token", "ident", ident)
+	}
+
+	s, err := NewIfStatement(value, cons, alt)
+	if err != nil {
+		return nil, err
+	}
+
+	is := s.(*IfStatement)
+	is.Token = i
+	is.Binding = string(i.Lit)
+	return is, nil
+}
+
+func NewElseIfLetBlock(ident, value, cons, alt Attrib) (*BlockStatement, error) {
+	s, err := NewIfLetStatement(ident, value, cons, alt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BlockStatement{Statements: []Statement{s}}, nil
+}
+
+func NewForStatement(tok, cond, block Attrib) (Statement, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewForStatement", "*token.Token", "tok", tok)
+	}
+
+	c, ok := cond.(Expression)
+	if !ok {
+		return nil, Error("NewForStatement", "Expression", "cond", cond)
+	}
+
+	b, ok := block.(*BlockStatement)
+	if !ok {
+		return nil, Error("NewForStatement", "BlockStatement", "block", block)
+	}
+
+	return &ForStatement{Token: t, Condition: c, BlockStatement: b}, nil
+}
+
+// start of a range and whether its end is included
+type rangeStart struct {
+	start     Expression
+	inclusive bool
+}
+
+func NewRangeStart(start, rng Attrib) (*rangeStart, error) {
+	s, ok := start.(Expression)
+	if !ok {
+		return nil, Error("NewRangeStart", "Expression", "start", start)
+	}
+
+	r, ok := rng.(*token.Token)
+	if !ok {
+		return nil, Error("NewRangeStart", "*token.Token", "rng", rng)
+	}
+
+	return &rangeStart{start: s, inclusive: string(r.Lit) == "..="}, nil
+}
+
+func NewForInStatement(tok, iter, iterable, block Attrib) (Statement, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewForInStatement", "*token.Token", "tok", tok)
+	}
+
+	i, ok := iter.(*token.Token)
+	if !ok {
+		return nil, Error("NewForInStatement", "*token.Token", "iter", iter)
+	}
+
+	e, ok := iterable.(Expression)
+	if !ok {
+		return nil, Error("NewForInStatement", "Expression", "iterable", iterable)
+	}
+
+	b, ok := block.(*BlockStatement)
+	if !ok {
+		return nil, Error("NewForInStatement", "BlockStatement", "block", block)
+	}
+
+	return &ForInStatement{Token: t, Iterator: string(i.Lit), Iterable: e, Block: b}, nil
+}
+
+func NewForRangeStatement(tok, iter, start, end, step, block Attrib) (Statement, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewForRangeStatement", "*token.Token", "tok", tok)
+	}
+
+	i, ok := iter.(*token.Token)
+	if !ok {
+		return nil, Error("NewForRangeStatement", "*token.Token", "iter", iter)
+	}
+
+	s, ok := start.(*rangeStart)
+	if !ok {
+		return nil, Error("NewForRangeStatement", "rangeStart", "start", start)
+	}
+
+	e, ok := end.(Expression)
+	if !ok {
+		return nil, Error("NewForRangeStatement", "Expression", "end", end)
+	}
+
+	var st Expression
+	if step != nil {
+		st, ok = step.(Expression)
+		if !ok {
+			return nil, Error("NewForRangeStatement", "Expression", "step", step)
+		}
+	}
+
+	b, ok := block.(*BlockStatement)
+	if !ok {
+		return nil, Error("NewForRangeStatement", "BlockStatement", "block", block)
+	}
+
+	return &ForRangeStatement{Token: t, Iterator: string(i.Lit), Start: s.start, End: e, Step: st,
+		Inclusive: s.inclusive, Block: b}, nil
+}
+
+func NewInfixExpression(left, right, oper Attrib) (Expression, error) {
+	l, ok := left.(Expression)
+	if !ok {
+		fmt.Println(left)
+		return nil, Error("NewInfixExpression", "Expression", "left", left)
+	}
+
+	o, ok := oper.(*token.Token)
+	if !ok {
+		return nil, Error("NewInfixExpression", "*token.Token", "oper", oper)
+	}
+
+	r, ok := right.(Expression)
+	if !ok {
+		return nil, Error("NewInfixExpression", "Expression", "right", right)
+	}
+
+	return &InfixExpression{Left: l, Operator: string(o.Lit), Right: r, Token: o}, nil
+}
+
+func NewPrefixExpression(oper, right Attrib) (Expression, error) {
+	o, ok := oper.(*token.Token)
+	if !ok {
+		return nil, Error("NewPrefixExpression", "*token.Token", "oper", oper)
+	}
+
+	r, ok := right.(Expression)
+	if !ok {
+		return nil, Error("NewPrefixExpression", "Expression", "right", right)
+	}
+
+	return &PrefixExpression{Operator: string(o.Lit), Right: r, Token: o}, nil
+}
+
+func NewIndexExpression(left, tok, index Attrib) (*IndexExpression, error) {
+	l, ok := left.(Expression)
+	if !ok {
+		return nil, Error("NewIndexExpression", "Expression", "left", left)
+	}
+
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewIndexExpression", "*token.Token", "tok", tok)
+	}
+
+	i, ok := index.(Expression)
+	if !ok {
+		return nil, Error("NewIndexExpression", "Expression", "index", index)
+	}
+
+	return &IndexExpression{Token: t, Left: l, Index: i}, nil
+}
+
+func NewFieldExpression(left, tok, field Attrib) (*FieldExpression, error) {
+	l, ok := left.(Expression)
+	if !ok {
+		return nil, Error("NewFieldExpression", "Expression", "left", left)
+	}
+
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewFieldExpression", "*token.Token", "tok", tok)
+	}
+
+	f, ok := field.(*token.Token)
+	if !ok {
+		return nil, Error("NewFieldExpression", "*token.Token", "field", field)
+	}
+
+	return &FieldExpression{Token: t, Left: l, Field: string(f.Lit)}, nil
+}
+
+func NewVariantExpression(enum, variant, args Attrib) (Expression, error) {
+	e, ok := enum.(*token.Token)
+	if !ok {
+		return nil, Error("NewVariantExpression", "*token.Token", "enum", enum)
+	}
+
+	v, ok := variant.(*token.Token)
+	if !ok {
+		return nil, Error("NewVariantExpression", "*token.Token", "variant", variant)
+	}
+
+	a := []Expression{}
+	if args != nil {
+		a, ok = args.([]Expression)
+		if !ok {
+			return nil, Error("NewVariantExpression", "[]Expression", "args", args)
+		}
+	}
+
+	return &VariantExpression{Token: e, Enum: string(e.Lit), Variant: string(v.Lit), Args: a}, nil
+}
+
+func NewMatchExpression(tok, value, arms Attrib) (Expression, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewMatchExpression", "*token.Token", "tok", tok)
+	}
+
+	v, ok := value.(Expression)
+	if !ok {
+		return nil, Error("NewMatchExpression", "Expression", "value", value)
+	}
+
+	as, ok := arms.([]MatchArm)
+	if !ok {
+		return nil, Error("NewMatchExpression", "[]MatchArm", "arms", arms)
+	}
+
+	return &MatchExpression{Token: t, Value: v, Arms: as}, nil
+}
+
+func NewNoneLiteral(tok Attrib) (Expression, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewNoneLiteral", "*token.Token", "tok", tok)
+	}
+
+	return &NoneLiteral{Token: t}, nil
+}
+
+func NewTupleLiteral(tok, first, second, rest Attrib) (Expression, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewTupleLiteral", "*token.Token", "tok", tok)
+	}
+
+	f, ok := first.(Expression)
+	if !ok {
+		return nil, Error("NewTupleLiteral", "Expression", "first", first)
+	}
+
+	s, ok := second.(Expression)
+	if !ok {
+		return nil, Error("NewTupleLiteral", "Expression", "second", second)
+	}
+
+	r, ok := rest.([]Expression)
+	if !ok {
+		return nil, Error("NewTupleLiteral", "[]Expression", "rest", rest)
+	}
+
+	return &TupleLiteral{Token: t, Elements: append([]Expression{f, s}, r...)}, nil
+}
+
+func NewListLiteral(tok, elems Attrib) (Expression, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewListLiteral", "*token.Token", "tok", tok)
+	}
+
+	e := []Expression{}
+	if elems != nil {
+		e, ok = elems.([]Expression)
+		if !ok {
+			return nil, Error("NewListLiteral", "[]Expression", "elems", elems)
+		}
+	}
+
+	return &ListLiteral{Token: t, Elements: e}, nil
+}
+
+func NewMapLiteral(tok, entries Attrib) (Expression, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewMapLiteral", "*token.Token", "tok", tok)
+	}
+
+	e := []MapEntry{}
+	if entries != nil {
+		e, ok = entries.([]MapEntry)
+		if !ok {
+			return nil, Error("NewMapLiteral", "[]MapEntry", "entries", entries)
+		}
+	}
+
+	return &MapLiteral{Token: t, Entries: e}, nil
+}
+
+// first entry followed by the rest of the list
+func NewMapEntries(key, value, rest Attrib) ([]MapEntry, error) {
+	first, err := AppendMapEntry([]MapEntry{}, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	es, ok := rest.([]MapEntry)
+	if !ok {
+		return nil, Error("NewMapEntries", "[]MapEntry", "rest", rest)
+	}
+
+	return append(first, es...), nil
+}
+
+func NewMapEntryList() ([]MapEntry, error) {
+	return []MapEntry{}, nil
+}
+
+func AppendMapEntry(entries, key, value Attrib) ([]MapEntry, error) {
+	es, ok := entries.([]MapEntry)
+	if !ok {
+		return nil, Error("AppendMapEntry", "[]MapEntry", "entries", entries)
+	}
+
+	k, ok := key.(Expression)
+	if !ok {
+		return nil, Error("AppendMapEntry", "Expression", "key", key)
+	}
+
+	v, ok := value.(Expression)
+	if !ok {
+		return nil, Error("AppendMapEntry", "Expression", "value", value)
+	}
+
+	return append(es, MapEntry{Key: k, Value: v}), nil
+}
+
+func NewIntegerLiteral(integer Attrib) (Expression, error) {
+	intLit, ok := integer.(*token.Token)
+	if !ok {
+		return nil, Error("NewIntegerLiteral", "*token.Token", "integer", integer)
+	}
+
+	return &IntegerLiteral{Token: intLit, Value: string(intLit.Lit)}, nil
+}
+
+func NewFloatLiteral(float, fraction Attrib) (Expression, error) {
+	floatLit, ok := float.(*token.Token)
+	if !ok {
+		return nil, Error("NewFloatLiteral", "*token.Token", "float", float)
+	}
+
+	value := string(floatLit.Lit)
+	if fraction != nil { // the whole part and fraction are scanned apart
+		f, ok := fraction.(*token.Token)
+		if !ok {
+			return nil, Error("NewFloatLiteral", "*token.Token", "fraction", fraction)
+		}
+		if f.Pos.Offset != floatLit.Pos.Offset+len(floatLit.Lit) {
+			return nil, fmt.Errorf("invalid float literal %s %s", value, string(f.Lit))
+		}
+		value += string(f.Lit)
+	}
+
+	return &FloatLiteral{Token: floatLit, Value: value}, nil
+}
+
+func NewStringLiteral(str Attrib) (Expression, error) {
+	s, ok := str.(*token.Token)
+	if !ok {
+		return nil, Error("NewStringLiteral", "*token.Token", "str", str)
+	}
+
+	v, err := unescape(string(s.Lit))
+	if err != nil {
+		return nil, err
+	}
+
+	return &StringLiteral{Value: v, Token: s}, nil
+}
+
+// strip the quotes of a string literal and decode its escapes,
+// the lexer has already checked their shape
+func unescape(lit string) (string, error) {
+	lit = lit[1 : len(lit)-1]
+	var b strings.Builder
+
+	for i := 0; i < len(lit); i++ {
+		if lit[i] != '\\' {
+			b.WriteByte(lit[i])
+			continue
+		}
+
+		i++
+		switch lit[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		case 'u': // \u{hex}
+			end := i + strings.IndexByte(lit[i:], '}')
+			code, err := strconv.ParseUint(lit[i+2:end], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid unicode escape \\u{%s}", lit[i+2:end])
+			}
+			b.WriteRune(rune(code))
+			i = end
+		default: // quote and backslash
+			b.WriteByte(lit[i])
+		}
+	}
+	return b.String(), nil
+}
+
+func NewIdentInit(ident, expr Attrib) (Statement, error) {
+	e, ok := expr.(Expression)
+	if !ok {
+		return nil, Error("NewIdentInit", "Expression", "expr", expr)
+	}
+
+	return &InitStatement{Location: string(ident.(*token.Token).Lit), Token: ident.(*token.Token), Expr: e}, nil
+}
+
+func NewTypedIdentInit(ident, kind, expr Attrib) (Statement, error) {
+	k, ok := kind.(string)
+	if !ok {
+		return nil, Error("NewTypedIdentInit", "string", "kind", kind)
+	}
+
+	s, err := NewIdentInit(ident, expr)
+	if err != nil {
+		return nil, err
+	}
+
+	init := s.(*InitStatement)
+	init.Declared = k
+	return init, nil
+}
+
+func NewTupleInit(tok, idents, expr Attrib) (Statement, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewTupleInit", "*token.Token", "tok", tok)
+	}
+
+	is, ok := idents.([]string)
+	if !ok {
+		return nil, Error("NewTupleInit", "[]string", "idents", idents)
+	}
+
+	e, ok := expr.(Expression)
+	if !ok {
+		return nil, Error("NewTupleInit", "Expression", "expr", expr)
+	}
+
+	return &TupleInitStatement{Token: t, Locations: is, Expr: e}, nil
+}
+
+func NewIdentExpression(ident Attrib) (*Identifier, error) {
+	return &Identifier{Value: string(ident.(*token.Token).Lit), Token: ident.(*token.Token)}, nil
+}
+
+func NewBoolExpression(val Attrib) (Expression, error) {
+	return &Boolean{Value: val.(bool)}, nil
+}
+
+func NewReturnStatement(exp Attrib) (Statement, error) {
+	if exp == nil { // a bare return
+		return &ReturnStatement{}, nil
+	}
+
+	e, ok := exp.(Expression)
+	if !ok {
+		return nil, Error("NewReturnExpression", "Expression", "exp", exp)
+	}
+	return &ReturnStatement{ReturnValue: e}, nil
+}
+
+func NewBreakStatement(tok Attrib) (Statement, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewBreakStatement", "*token.Token", "tok", tok)
+	}
+	return &BreakStatement{Token: t}, nil
+}
+
+func NewContinueStatement(tok Attrib) (Statement, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewContinueStatement", "*token.Token", "tok", tok)
+	}
+	return &ContinueStatement{Token: t}, nil
+}
+
+func NewFunctionCall(name, args Attrib) (Expression, error) {
+	n, ok := name.(*token.Token)
+	if !ok {
+		return nil, fmt.Errorf("invalid type of name. got=%T", name)
+	}
+
+	a := []Expression{}
+	if args != nil {
+		var ok bool
+		a, ok = args.([]Expression)
+		if !ok {
+			return nil, Error("NewFunctionCall", "[]Expression", "args", args)
+		}
+	}
+
+	return &FunctionCall{Name: string(n.Lit), Args: a, Token: n}, nil
+}
+
+// the first parameter is passed apart from the rest, see grammer.bnf
+func NewFunctionLiteral(tok, arg, kind, rest, ret, block Attrib) (Expression, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewFunctionLiteral", "*token.Token", "tok", tok)
+	}
+
+	args := []FormalArg{}
+	if arg != nil {
+		first, err := AppendFormalArgs(args, arg, kind)
+		if err != nil {
+			return nil, err
+		}
+		args = first
+	}
+
+	if rest != nil {
+		r, ok := rest.([]FormalArg)
+		if !ok {
+			return nil, Error("NewFunctionLiteral", "[]FormalArg", "rest", rest)
+		}
+		args = append(args, r...)
+	}
+
+	r := "Nothing" // procedures leave out the return type
+	if ret != nil {
+		r, ok = ret.(string)
+		if !ok {
+			return nil, Error("NewFunctionLiteral", "string", "ret", ret)
+		}
+	}
+
+	b, ok := block.(*BlockStatement)
+	if !ok {
+		return nil, Error("NewFunctionLiteral", "BlockStatement", "block", block)
+	}
+
+	return &FunctionLiteral{Token: t, Parameters: args, Return: r, Body: b}, nil
+}
+
+// calls by name, method calls and variant constructors keep their own
+// nodes, anything else calls a function value
+func NewCallExpression(fn, tok, args Attrib) (Expression, error) {
+	t, ok := tok.(*token.Token)
+	if !ok {
+		return nil, Error("NewCallExpression", "*token.Token", "tok", tok)
+	}
+
+	a := []Expression{}
+	if args != nil {
+		a, ok = args.([]Expression)
+		if !ok {
+			return nil, Error("NewCallExpression", "[]Expression", "args", args)
+		}
+	}
+
+	switch f := fn.(type) {
+	case *Identifier:
+		return NewFunctionCall(f.Token, args)
+	case *FieldExpression:
+		return &FunctionCall{Token: f.Token, Name: f.Field, Args: append([]Expression{f.Left}, a...), Method: true}, nil
+	case *VariantExpression:
+		if len(f.Args) == 0 {
+			f.Args = a
+			return f, nil
+		}
+	}
+
+	f, ok := fn.(Expression)
+	if !ok {
+		return nil, Error("NewCallExpression", "Expression", "fn", fn)
+	}
+
+	return &CallExpression{Token: t, Function: f, Args: a}, nil
+}
+
+// first formal arg followed by the rest of the list
+func NewFormalArgs(arg, kind, rest Attrib) ([]FormalArg, error) {
+	first, err := AppendFormalArgs([]FormalArg{}, arg, kind)
+	if err != nil {
+		return nil, err
+	}
+
+	as, ok := rest.([]FormalArg)
+	if !ok {
+		return nil, Error("NewFormalArgs", "[]FormalArg", "rest", rest)
+	}
+
+	return append(first, as...), nil
+}
+
+func NewFormalArg() ([]FormalArg, error) {
+	return []FormalArg{}, nil
+}
+
+func AppendFormalArgs(args, arg, kind Attrib) ([]FormalArg, error) {
+	as, ok := args.([]FormalArg)
+	if !ok {
+		return nil, Error("AppendFormalArgs", "[]FormalArg", "args", args)
+	}
+
+	a, ok := arg.(*token.Token)
+	if !ok {
+		return nil, Error("AppendFormalArgs", "*token.Token", "arg", arg)
+	}
+
+	k, ok := kind.(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid type of kind. got=%T", kind)
+	}
+
+	return append(as, FormalArg{string(a.Lit), k}), nil
+}
+
+// first arg followed by the rest of the list
+func NewArgs(expr, rest Attrib) ([]Expression, error) {
+	as, ok := rest.([]Expression)
+	if !ok {
+		return nil, Error("NewArgs", "[]Expression", "rest", rest)
+	}
+
+	e, ok := expr.(Expression)
+	if !ok {
+		return nil, Error("NewArgs", "Expression", "expr", expr)
+	}
+
+	return append([]Expression{e}, as...), nil
+}
+
+func NewArg() ([]Expression, error) {
+	return []Expression{}, nil
+}
+
+func AppendArgs(expr, args Attrib) ([]Expression, error) {
+	as, ok := args.([]Expression)
+	if !ok {
+		return nil, Error("AppendFormalArgs", "[]FormalArgs", "args", args)
+	}
+
+	e, ok := expr.(Expression)
+	if !ok {
+		return nil, Error("AppendFormalArgs", "Expression", "expr", expr)
+	}
+
+	return append(as, e), nil
+}
+
+func NewTypeName(name Attrib) (string, error) {
+	n, ok := name.(*token.Token)
+	if !ok {
+		return "", Error("NewTypeName", "*token.Token", "name", name)
+	}
+	return string(n.Lit), nil
+}
+
+// a type applied to type arguments, e.g. List[Int]
+func NewGenericType(name, args Attrib) (string, error) {
+	n, err := NewTypeName(name)
+	if err != nil {
+		return "", err
+	}
+
+	as, ok := args.([]string)
+	if !ok {
+		return "", Error("NewGenericType", "[]string", "args", args)
+	}
+
+	return n + "[" + strings.Join(as, ", ") + "]", nil
+}
+
+// a tuple type has at least two elements, e.g. (Int, String)
+func NewTupleType(kinds Attrib) (string, error) {
+	ks, ok := kinds.([]string)
+	if !ok {
+		return "", Error("NewTupleType", "[]string", "kinds", kinds)
+	}
+
+	if len(ks) < 2 {
+		return "", fmt.Errorf("tuple type needs at least two elements, got %d", len(ks))
+	}
+
+	return "(" + strings.Join(ks, ", ") + ")", nil
+}
+
+func NewFuncType(params, ret Attrib) (string, error) {
+	ps := []string{}
+	if params != nil {
+		var ok bool
+		ps, ok = params.([]string)
+		if !ok {
+			return "", Error("NewFuncType", "[]string", "params", params)
+		}
+	}
+
+	r, ok := ret.(string)
+	if !ok {
+		return "", Error("NewFuncType", "string", "ret", ret)
+	}
+
+	return "func(" + strings.Join(ps, ", ") + ") " + r, nil
+}
+
+func NewOptionalType(kind Attrib) (string, error) {
+	k, ok := kind.(string)
+	if !ok {
+		return "", Error("NewOptionalType", "string", "kind", kind)
+	}
+
+	return k + "?", nil
+}
+
+func NewTypeList(kind Attrib) ([]string, error) {
+	return AppendType([]string{}, kind)
+}
+
+func AppendType(kinds, kind Attrib) ([]string, error) {
+	ks, ok := kinds.([]string)
+	if !ok {
+		return nil, Error("AppendType", "[]string", "kinds", kinds)
+	}
+
+	k, ok := kind.(string)
+	if !ok {
+		return nil, Error("AppendType", "string", "kind", kind)
+	}
+
+	return append(ks, k), nil
 }
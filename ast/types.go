@@ -41,6 +41,74 @@ type FunctionStatement struct {
 	Parameters []FormalArg     `json:"params"`
 	Body       *BlockStatement `json:"body"`
 	Return     string          `json:"return"`
+	Receiver   *FormalArg      `json:"receiver,omitempty"`   // set for methods
+	TypeParams []TypeParam     `json:"typeParams,omitempty"` // set for generic functions
+}
+
+// T in func max[T Ordered](...), the constraint is an interface
+type TypeParam struct {
+	Name       string `json:"name"`
+	Constraint string `json:"constraint,omitempty"`
+}
+
+type StructStatement struct {
+	Token  *token.Token `json:"-"`
+	Name   string       `json:"name"`
+	Fields []FormalArg  `json:"fields"`
+}
+
+// type UserId = Int is an alias, type Meters Int a distinct newtype
+type TypeStatement struct {
+	Token *token.Token `json:"-"`
+	Name  string       `json:"name"`
+	Type  string       `json:"type"`
+	Alias bool         `json:"alias"`
+}
+
+// type Shape interface { area() Float } lists the methods a type needs
+type InterfaceStatement struct {
+	Token   *token.Token `json:"-"`
+	Name    string       `json:"name"`
+	Methods []MethodSpec `json:"methods"`
+}
+
+type MethodSpec struct {
+	Name       string      `json:"name"`
+	Parameters []FormalArg `json:"params"`
+	Return     string      `json:"return"`
+}
+
+type EnumStatement struct {
+	Token    *token.Token `json:"-"`
+	Name     string       `json:"name"`
+	Variants []Variant    `json:"variants"`
+}
+
+type Variant struct {
+	Name  string   `json:"name"`
+	Types []string `json:"types"` // payload
+}
+
+type MatchStatement struct {
+	Token *token.Token `json:"-"`
+	Type  string       `json:"-"` // matched enum
+	Value Expression   `json:"value"`
+	Arms  []MatchArm   `json:"arms"`
+}
+
+// an arm runs a block in a statement and gives a value in an expression
+type MatchArm struct {
+	Pattern Pattern         `json:"pattern"`
+	Block   *BlockStatement `json:"block,omitempty"`
+	Value   Expression      `json:"value,omitempty"`
+}
+
+// Enum::Variant(a, b), a wildcard has no enum
+type Pattern struct {
+	Token    *token.Token `json:"-"`
+	Enum     string       `json:"enum"`
+	Variant  string       `json:"variant"`
+	Bindings []string     `json:"bindings"`
 }
 
 type FormalArg struct {
@@ -54,6 +122,32 @@ type ForStatement struct {
 	BlockStatement *BlockStatement `json:"block"`
 }
 
+type ForRangeStatement struct {
+	Token     *token.Token    `json:"-"`
+	Iterator  string          `json:"iterator"`
+	Start     Expression      `json:"start"`
+	End       Expression      `json:"end"`
+	Step      Expression      `json:"step"`
+	Inclusive bool            `json:"inclusive"`
+	Block     *BlockStatement `json:"block"`
+}
+
+type BreakStatement struct {
+	Token *token.Token `json:"-"`
+}
+
+type ContinueStatement struct {
+	Token *token.Token `json:"-"`
+}
+
+type ForInStatement struct {
+	Token    *token.Token    `json:"-"`
+	Type     string          `json:"-"`
+	Iterator string          `json:"iterator"`
+	Iterable Expression      `json:"iterable"`
+	Block    *BlockStatement `json:"block"`
+}
+
 type ReturnStatement struct {
 	Token       *token.Token `json:"-"`
 	ReturnValue Expression   `json:"return"`
@@ -64,8 +158,12 @@ type BlockStatement struct {
 	Statements []Statement  `json:"statements"`
 }
 
+// with a binding, if let v = maybe { } runs the block when
+// the optional condition holds a value and binds it to v
 type IfStatement struct {
 	Token       *token.Token    `json:"-"`
+	Type        string          `json:"-"` // optional type of a bound condition
+	Binding     string          `json:"binding,omitempty"`
 	Condition   Expression      `json:"condition"`
 	Block       *BlockStatement `json:"block"`
 	Alternative *BlockStatement `json:"alternative"`
@@ -78,14 +176,37 @@ type ExpressionStatement struct {
 
 type InitStatement struct {
 	Token    *token.Token `json:"-"`
+	Type     string       `json:"-"`
+	Declared string       `json:"declared"`
 	Expr     Expression   `json:"expression"`
 	Location string       `json:"location"`
 }
 
+// let (a, b) = pair;
+type TupleInitStatement struct {
+	Token     *token.Token `json:"-"`
+	Type      string       `json:"-"`
+	Locations []string     `json:"locations"`
+	Expr      Expression   `json:"expression"`
+}
+
+type IndexAssignStatement struct {
+	Token *token.Token     `json:"-"`
+	Left  *IndexExpression `json:"left"`
+	Right Expression       `json:"right"`
+}
+
+type FieldAssignStatement struct {
+	Token *token.Token     `json:"-"`
+	Left  *FieldExpression `json:"left"`
+	Right Expression       `json:"right"`
+}
+
 // Expressions
 type Identifier struct {
-	Token *token.Token `json:"-"`
-	Value string       `json:"value"`
+	Token    *token.Token `json:"-"`
+	Value    string       `json:"value"`
+	Function bool         `json:"-"` // names a function rather than a variable
 }
 
 type Boolean struct {
@@ -98,6 +219,11 @@ type IntegerLiteral struct {
 	Value string       `json:"value"`
 }
 
+type FloatLiteral struct {
+	Token *token.Token `json:"-"`
+	Value string       `json:"value"`
+}
+
 type StringLiteral struct {
 	Token *token.Token `json:"-"`
 	Value string       `json:"value"`
@@ -111,9 +237,94 @@ type InfixExpression struct {
 	Operator string       `json:"operator"`
 }
 
-type FunctionCall struct {
+type PrefixExpression struct {
+	Token    *token.Token `json:"-"`
+	Type     string       `json:"-"`
+	Operator string       `json:"operator"`
+	Right    Expression   `json:"right"`
+}
+
+type ListLiteral struct {
+	Token    *token.Token `json:"-"`
+	Type     string       `json:"-"`
+	Elements []Expression `json:"elements"`
+}
+
+type MapLiteral struct {
+	Token   *token.Token `json:"-"`
+	Type    string       `json:"-"`
+	Entries []MapEntry   `json:"entries"`
+}
+
+type VariantExpression struct {
+	Token   *token.Token `json:"-"`
+	Enum    string       `json:"enum"`
+	Variant string       `json:"variant"`
+	Args    []Expression `json:"args"`
+}
+
+type MatchExpression struct {
+	Token  *token.Token `json:"-"`
+	Type   string       `json:"-"` // matched enum
+	Result string       `json:"-"`
+	Value  Expression   `json:"value"`
+	Arms   []MatchArm   `json:"arms"`
+}
+
+type NoneLiteral struct {
 	Token *token.Token `json:"-"`
-	Name  string       `json:"name"`
-	Args  []Expression `json:"args"`
-	Type  string       `json:"type"`
+	Type  string       `json:"-"`
+}
+
+type TupleLiteral struct {
+	Token    *token.Token `json:"-"`
+	Type     string       `json:"-"`
+	Elements []Expression `json:"elements"`
+}
+
+type MapEntry struct {
+	Key   Expression `json:"key"`
+	Value Expression `json:"value"`
+}
+
+type IndexExpression struct {
+	Token *token.Token `json:"-"`
+	Type  string       `json:"-"`
+	Left  Expression   `json:"left"`
+	Index Expression   `json:"index"`
+}
+
+type FieldExpression struct {
+	Token *token.Token `json:"-"`
+	Type  string       `json:"-"` // struct type
+	Left  Expression   `json:"left"`
+	Field string       `json:"field"`
+}
+
+type FunctionCall struct {
+	Token    *token.Token `json:"-"`
+	Name     string       `json:"name"`
+	Args     []Expression `json:"args"`
+	Type     string       `json:"type"`
+	Method   bool         `json:"method"`             // x.f(a) is f with args x, a
+	TypeArgs []string     `json:"typeArgs,omitempty"` // inferred for generic functions
+	Value    bool         `json:"-"`                  // calls a variable of function type
+}
+
+// func(x Int) Int { ... } used as a value
+type FunctionLiteral struct {
+	Token      *token.Token    `json:"-"`
+	Type       string          `json:"-"` // function type
+	Parameters []FormalArg     `json:"params"`
+	Return     string          `json:"return"`
+	Body       *BlockStatement `json:"body"`
+	Captures   []string        `json:"-"` // outside variables the body reads
+}
+
+// f(x) where f is any expression of function type
+type CallExpression struct {
+	Token    *token.Token `json:"-"`
+	Type     string       `json:"-"` // function type
+	Function Expression   `json:"function"`
+	Args     []Expression `json:"args"`
 }
@@ -36,7 +36,7 @@ func TestAST(t *testing.T) {
 			ast.FunctionStatement{Name: "add", Return: "Int", Parameters: []ast.FormalArg{}, Body: &ast.BlockStatement{
 				Statements: []ast.Statement{ast.ReturnStatement{ReturnValue: ast.InfixExpression{Left: ast.StringLiteral{Value: "x"}, Operator: "+", Right: ast.StringLiteral{Value: "y"}}}}}}},
 		Statements: []ast.Statement{
-			ast.InitStatement{Expr: ast.StringLiteral{Value: "\"test\""}, Location: "five"},
+			ast.InitStatement{Expr: ast.StringLiteral{Value: "test"}, Location: "five"},
 			ast.InitStatement{Expr: ast.StringLiteral{Value: "10"}, Location: "ten"},
 			ast.AssignStatement{Left: ast.Identifier{Value: "ten"}, Right: ast.IntegerLiteral{Value: "4"}},
 			ast.InitStatement{Expr: ast.IntegerLiteral{Value: "4"}, Location: "result"},
@@ -60,3 +60,30 @@ func TestAST(t *testing.T) {
 	}
 
 }
+
+func TestStringLiteral(t *testing.T) {
+	tests := []struct {
+		src   string
+		value string
+	}{
+		{`"hello world!";`, "hello world!"},
+		{`"a, b. (c): [d] {e} #1 $2 ~";`, "a, b. (c): [d] {e} #1 $2 ~"},
+		{`"line\nbreak\ttab\rreturn";`, "line\nbreak\ttab\rreturn"},
+		{`"say \"hi\" \\ bye";`, `say "hi" \ bye`},
+		{`"caf\u{e9} \u{1F600}";`, "caf\u00e9 \U0001F600"},
+		{`"naïve 日本";`, "naïve 日本"},
+		{`"";`, ""}}
+
+	for i, test := range tests {
+		program := Parse(test.src)
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		str, ok := stmt.Expression.(*ast.StringLiteral)
+		if !ok {
+			t.Fatalf("test [%d] expected StringLiteral. got=%T", i, stmt.Expression)
+		}
+
+		if str.Value != test.value {
+			t.Fatalf("test [%d] wrong value. expected=%q, got=%q", i, test.value, str.Value)
+		}
+	}
+}
@@ -2,9 +2,27 @@
 
 #include <iostream>
 #include <string>
+#include <cstdlib>
+#include <cmath>
+#include <sstream>
+#include <iomanip>
+#include <vector>
+#include <tuple>
+#include <utility>
+#include <memory>
+#include <functional>
+#include <type_traits>
+#include <climits>
 
 using namespace std;
 
+// report an unrecoverable error and stop the program
+// (static since this file is both included and compiled)
+static void runtimeError(string msg) {
+	cerr << "runtime error: " << msg << endl;
+	exit(1);
+}
+
 
 // Nothing Class
 class Nothing {
@@ -17,6 +35,7 @@ public:
 	string val;
 	Nothing PRINT(void) {
 		cout << val << endl;
+		return Nothing();
 	}
 };
 
@@ -30,19 +49,40 @@ public:
 		val = x;
 	}
 
-	Bool And(Bool x) {
+	Bool AND(Bool x) {
 		if (val == False || x.val == False) {
 			return Bool(False);
 		}
-		return Bool(False);
+		return Bool(True);
 	}
 
-	Bool Or(Bool x) {
+	Bool OR(Bool x) {
 		if (val == True || x.val == True) {
 			return Bool(True);
 		}
 		return Bool(False);
 	}
+
+	Bool NOT() {
+		if (val == True) {
+			return Bool(False);
+		}
+		return Bool(True);
+	}
+
+	Bool EQ(Bool x) {
+		if (val == x.val) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool NE(Bool x) {
+		if (val != x.val) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
 };
 
 // String Class
@@ -62,8 +102,46 @@ public:
 			return Bool(False);
 		}
 	}
+
+	Bool NE(String str) {
+		if (val != str.val) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	// ordering is lexicographic by byte
+	Bool LT(String str) {
+		if (val < str.val) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool GT(String str) {
+		if (val > str.val) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool LE(String str) {
+		if (val <= str.val) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool GE(String str) {
+		if (val >= str.val) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
 };
 
+class Float;
+
 // Int Class
 class Int: public Base {
 public:
@@ -85,14 +163,57 @@ public:
 		return Int(valInt * num.valInt);
 	}
 
+	// truncates toward zero
+	Int DIV(Int num) {
+		if (num.valInt == 0) {
+			runtimeError("integer division by zero");
+		}
+		return Int(valInt / num.valInt);
+	}
+
+	// result takes the sign of the dividend
+	Int MOD(Int num) {
+		if (num.valInt == 0) {
+			runtimeError("integer modulo by zero");
+		}
+		return Int(valInt % num.valInt);
+	}
+
+	Int NEG() {
+		return Int(-valInt);
+	}
+
+	Float FLOAT();
+
 	Bool GT(Int num) {
-		if (valInt < num.valInt) {
+		if (valInt <= num.valInt) {
 			return Bool(False);
 		} else {
 			return Bool(True);
 		}
 	}
 
+	Bool LT(Int num) {
+		if (valInt < num.valInt) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool GE(Int num) {
+		if (valInt >= num.valInt) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool LE(Int num) {
+		if (valInt <= num.valInt) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
 	Bool EQ(Int num) {
 		if (valInt == num.valInt) {
 			return Bool(True);
@@ -100,7 +221,362 @@ public:
 		return Bool(False);
 	}
 
+	Bool NE(Int num) {
+		if (valInt != num.valInt) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
 	String Stringify() {
 		return String(val);
 	}
-};
\ No newline at end of file
+};
+
+// print the shorter of 15 or 17 digits that reads back as the same
+// value, and always show it is a Float
+static string formatFloat(double x) {
+	ostringstream out;
+	out << setprecision(15) << x;
+	string s = out.str();
+	if (isfinite(x) && strtod(s.c_str(), nullptr) != x) {
+		out.str("");
+		out << setprecision(17) << x;
+		s = out.str();
+	}
+	if (s.find_first_of(".ein") == string::npos) {
+		s += ".0";
+	}
+	return s;
+}
+
+// Float Class
+class Float: public Base {
+public:
+	double valFloat;
+	Float(double x) {
+		val = formatFloat(x);
+		valFloat = x;
+	}
+
+	Float PLUS(Float num) {
+		return Float(valFloat + num.valFloat);
+	}
+
+	Float MINUS(Float num) {
+		return Float(valFloat - num.valFloat);
+	}
+
+	Float TIMES(Float num) {
+		return Float(valFloat * num.valFloat);
+	}
+
+	// follows IEEE 754, so dividing by zero gives inf or nan
+	Float DIV(Float num) {
+		return Float(valFloat / num.valFloat);
+	}
+
+	Float NEG() {
+		return Float(-valFloat);
+	}
+
+	Bool LT(Float num) {
+		if (valFloat < num.valFloat) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool GT(Float num) {
+		if (valFloat > num.valFloat) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool LE(Float num) {
+		if (valFloat <= num.valFloat) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool GE(Float num) {
+		if (valFloat >= num.valFloat) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool EQ(Float num) {
+		if (valFloat == num.valFloat) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	Bool NE(Float num) {
+		if (valFloat != num.valFloat) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+
+	// truncates toward zero
+	Int INT() {
+		// also false for nan
+		if (!(valFloat > (double)INT_MIN - 1 && valFloat < (double)INT_MAX + 1)) {
+			runtimeError("cannot convert " + val + " to Int");
+		}
+		return Int((int)valFloat);
+	}
+};
+
+inline Float Int::FLOAT() {
+	return Float(valInt);
+}
+
+template <typename T> class List;
+template <typename K, typename V> class Map;
+template <typename... T> class Tuple;
+template <typename T> class Optional;
+
+// text of a value, used when printing containers
+template <typename T>
+string show(T x) {
+	return x.val;
+}
+
+template <typename T>
+string show(List<T> xs) {
+	string s = "[";
+	for (size_t i = 0; i < xs.elems.size(); i++) {
+		if (i != 0) {
+			s += ", ";
+		}
+		s += show(xs.elems[i]);
+	}
+	return s + "]";
+}
+
+template <typename T>
+string show(Optional<T> x) {
+	if (x.value.empty()) {
+		return "none";
+	}
+	return show(x.value[0]);
+}
+
+template <typename... T>
+string show(Tuple<T...> t) {
+	string s = "(";
+	size_t i = 0;
+	apply([&](auto&... xs) {
+		((s += (i++ == 0 ? "" : ", ") + show(xs)), ...);
+	}, t.vals);
+	return s + ")";
+}
+
+template <typename K, typename V>
+string show(Map<K, V> m) {
+	string s = "{";
+	for (size_t i = 0; i < m.keys.size(); i++) {
+		if (i != 0) {
+			s += ", ";
+		}
+		s += show(m.keys[i]) + ": " + show(m.vals[i]);
+	}
+	return s + "}";
+}
+
+// functions have no text of their own
+template <typename R, typename... A>
+string show(function<R(A...)> f) {
+	return "<func>";
+}
+
+// List Class
+template <typename T>
+class List {
+public:
+	vector<T> elems;
+	List() {}
+	List(vector<T> xs) {
+		elems = xs;
+	}
+
+	Int LEN() {
+		return Int(elems.size());
+	}
+
+	Nothing APPEND(T x) {
+		elems.push_back(x);
+		return Nothing();
+	}
+
+	// bounds checked, returns a reference so elements can be assigned
+	T& INDEX(Int i) {
+		if (i.valInt < 0 || i.valInt >= (int)elems.size()) {
+			runtimeError("index " + i.val + " out of range for List of length " + to_string(elems.size()));
+		}
+		return elems[i.valInt];
+	}
+
+	vector<T> ITER() {
+		return elems;
+	}
+
+	Nothing PRINT() {
+		cout << show(*this) << endl;
+		return Nothing();
+	}
+};
+
+// Map Class
+// keys only need EQ, so lookup is linear and keeps insertion order
+template <typename K, typename V>
+class Map {
+public:
+	vector<K> keys;
+	vector<V> vals;
+	Map() {}
+
+	int find(K k) {
+		for (size_t i = 0; i < keys.size(); i++) {
+			if (k.EQ(keys[i]).val == True) {
+				return i;
+			}
+		}
+		return -1;
+	}
+
+	Int LEN() {
+		return Int(keys.size());
+	}
+
+	Bool HAS(K k) {
+		if (find(k) == -1) {
+			return Bool(False);
+		}
+		return Bool(True);
+	}
+
+	// returns a reference so values can be assigned
+	V& INDEX(K k) {
+		int i = find(k);
+		if (i == -1) {
+			runtimeError("key " + show(k) + " not in Map");
+		}
+		return vals[i];
+	}
+
+	Nothing SET(K k, V v) {
+		int i = find(k);
+		if (i == -1) {
+			keys.push_back(k);
+			vals.push_back(v);
+		} else {
+			vals[i] = v;
+		}
+		return Nothing();
+	}
+
+	Nothing DELETE(K k) {
+		int i = find(k);
+		if (i != -1) {
+			keys.erase(keys.begin() + i);
+			vals.erase(vals.begin() + i);
+		}
+		return Nothing();
+	}
+
+	vector<K> ITER() {
+		return keys;
+	}
+
+	Nothing PRINT() {
+		cout << show(*this) << endl;
+		return Nothing();
+	}
+};
+
+// Tuple Class
+// EQ is only instantiated for tuples whose elements have it
+template <typename... T>
+class Tuple {
+public:
+	tuple<T...> vals;
+	Tuple(T... xs) : vals(xs...) {}
+
+	Bool EQ(Tuple<T...> o) {
+		return eq(o, index_sequence_for<T...>{});
+	}
+
+	Bool NE(Tuple<T...> o) {
+		return EQ(o).NOT();
+	}
+
+	Nothing PRINT() {
+		cout << show(*this) << endl;
+		return Nothing();
+	}
+
+private:
+	template <size_t... I>
+	Bool eq(Tuple<T...> o, index_sequence<I...>) {
+		bool same = true;
+		((same = same && get<I>(vals).EQ(get<I>(o.vals)).val == True), ...);
+		if (same) {
+			return Bool(True);
+		}
+		return Bool(False);
+	}
+};
+
+// Optional Class
+// holds at most one value, plain values convert implicitly
+template <typename T>
+class Optional {
+public:
+	vector<T> value;
+	Optional() {}
+	Optional(T x) {
+		value.push_back(x);
+	}
+
+	// values that only convert to T, like a struct where an interface is held
+	template <typename U, typename = enable_if_t<!is_same_v<decay_t<U>, T> && !is_same_v<decay_t<U>, Optional<T>> && is_convertible_v<U, T>>>
+	Optional(U x) {
+		value.push_back(T(x));
+	}
+
+	bool HAS() {
+		return !value.empty();
+	}
+
+	Bool EQ(Optional<T> o) {
+		if (HAS() != o.HAS()) {
+			return Bool(False);
+		}
+		if (!HAS()) {
+			return Bool(True);
+		}
+		return value[0].EQ(o.value[0]);
+	}
+
+	Bool NE(Optional<T> o) {
+		return EQ(o).NOT();
+	}
+
+	Nothing PRINT() {
+		cout << show(*this) << endl;
+		return Nothing();
+	}
+};
+
+// show of a value whose type is only known on instantiation, for classes
+// whose own members would hide the free show
+template <typename T>
+string showValue(T x) {
+	return show(x);
+}
@@ -5,10 +5,34 @@ import (
 	"fmt"
 	"github.com/Lebonesco/go-compiler/ast"
 	"reflect"
+	"strings"
 )
 
+// number of loops enclosing the current statement
+var loopDepth int
+
+// declared return type of the function being checked
+var returnType string
+
+// generic function whose body is being checked
+var genericFunc *ast.FunctionStatement
+
+// a type parameter of one generic function passed into a type argument
+// of a call to another, growing when it is wrapped in a larger type
+type typeArgEdge struct {
+	from, to string
+	grows    bool
+}
+
+// edges found in generic function bodies
+var typeArgEdges []typeArgEdge
+
 func Checker(program *ast.Program) error {
 	env = NewEnvironment() // reset environment
+	TypeTable = builtinTypeTable()
+	loopDepth = 0
+	returnType = ""
+	genericFunc, typeArgEdges = nil, nil
 	_, err := checker(program)
 	return err
 }
@@ -24,19 +48,41 @@ func checker(node ast.Node) (string, error) {
 		return evalReturnStatement(node)
 	case *ast.IfStatement:
 		return evalIfStatement(node)
+	case *ast.ForStatement:
+		return evalForStatement(node)
+	case *ast.ForRangeStatement:
+		return evalForRangeStatement(node)
+	case *ast.ForInStatement:
+		return evalForInStatement(node)
+	case *ast.BreakStatement:
+		return evalBreakStatement(node)
+	case *ast.ContinueStatement:
+		return evalContinueStatement(node)
 	case *ast.ExpressionStatement:
 		return evalExpressionStatement(node)
 	case *ast.AssignStatement:
 		return evalAssignStatement(node)
+	case *ast.IndexAssignStatement:
+		return evalIndexAssignStatement(node)
 	case *ast.InitStatement:
 		return evalInitStatement(node)
+	case *ast.TupleInitStatement:
+		return evalTupleInitStatement(node)
 	case *ast.FunctionStatement:
 		return evalFunctionStatement(node)
+	case *ast.FieldAssignStatement:
+		return evalFieldAssignStatement(node)
+	case *ast.MatchStatement:
+		return evalMatchStatement(node)
 	// Expressions
 	case *ast.InfixExpression:
 		return evalInfixExpression(node)
+	case *ast.PrefixExpression:
+		return evalPrefixExpression(node)
 	case *ast.IntegerLiteral:
 		return evalInteger(node)
+	case *ast.FloatLiteral:
+		return evalFloat(node)
 	case *ast.StringLiteral:
 		return evalString(node)
 	case *ast.Boolean:
@@ -45,18 +91,74 @@ func checker(node ast.Node) (string, error) {
 		return evalIdentifier(node)
 	case *ast.FunctionCall:
 		return evalFunctionCall(node)
+	case *ast.CallExpression:
+		return evalCallExpression(node)
+	case *ast.FunctionLiteral:
+		return evalFunctionLiteral(node)
+	case *ast.ListLiteral:
+		return evalListLiteral(node, "")
+	case *ast.TupleLiteral:
+		return evalTupleLiteral(node, nil)
+	case *ast.NoneLiteral:
+		return "", errors.New("cannot infer type of none")
+	case *ast.MapLiteral:
+		return evalMapLiteral(node, "", "")
+	case *ast.IndexExpression:
+		return evalIndexExpression(node)
+	case *ast.FieldExpression:
+		return evalFieldExpression(node)
+	case *ast.VariantExpression:
+		return evalVariantExpression(node)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node)
 	}
 	return "", nil
 }
 
 func evalProgram(p *ast.Program) (string, error) {
+	// declare types first so functions can use them in any order
+	for _, decl := range p.Functions {
+		var err error
+		switch node := decl.(type) {
+		case *ast.StructStatement:
+			_, err = evalStructStatement(node)
+		case *ast.EnumStatement:
+			_, err = evalEnumStatement(node)
+		case *ast.TypeStatement:
+			_, err = evalTypeStatement(node)
+		case *ast.InterfaceStatement:
+			_, err = evalInterfaceStatement(node)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// collect every signature before checking any body so
+	// functions and methods can call each other in any order
+	for _, function := range p.Functions {
+		if node, ok := function.(*ast.FunctionStatement); ok {
+			if err := declareFunction(node); err != nil {
+				return "", err
+			}
+		}
+	}
+
 	for _, function := range p.Functions {
+		if _, ok := function.(*ast.FunctionStatement); !ok {
+			continue
+		}
+
 		_, err := checker(function)
 		if err != nil {
 			return "", err
 		}
 	}
 
+	if err := checkInstantiation(); err != nil {
+		return "", err
+	}
+
 	for _, statement := range p.Statements {
 		_, err := checker(statement)
 		if err != nil {
@@ -68,31 +170,193 @@ func evalProgram(p *ast.Program) (string, error) {
 
 // Statements
 func evalBlockStatement(node *ast.BlockStatement) (string, error) {
+	openScope()
+	defer closeScope()
+
+	// statements after the block has returned are still checked
+	returned := ""
 	for _, statement := range node.Statements {
 		result, err := checker(statement)
 		if err != nil {
 			return "", err
 		}
+		if returned != "" {
+			continue
+		}
 		if reflect.TypeOf(statement) == reflect.TypeOf(&ast.ReturnStatement{}) {
-			return result, nil
+			returned = result
+		}
+		// an if or match statement returns when all of its branches do
+		if reflect.TypeOf(statement) == reflect.TypeOf(&ast.IfStatement{}) && result != "" {
+			returned = result
 		}
+		if reflect.TypeOf(statement) == reflect.TypeOf(&ast.MatchStatement{}) && result != "" {
+			returned = result
+		}
+	}
+
+	if returned != "" {
+		return returned, nil
 	}
 	return NOTHING_TYPE, nil
 }
 
 func evalReturnStatement(node *ast.ReturnStatement) (string, error) {
-	res, err := checker(node.ReturnValue)
-	return res, err
+	if node.ReturnValue == nil { // a bare return leaves a procedure
+		if returnType != NOTHING_TYPE {
+			return "", errors.New("missing return value")
+		}
+		return NOTHING_TYPE, nil
+	}
+
+	res, err := evalExpected(node.ReturnValue, returnType)
+	if err != nil {
+		return "", err
+	}
+
+	if returnType != "" && res != returnType {
+		return "", errors.New("incorrect return type")
+	}
+	return res, nil
 }
 
+// returns the branches' return type if every branch returns
 func evalIfStatement(node *ast.IfStatement) (string, error) {
-	cond, _ := checker(node.Condition)
+	cond, err := checker(node.Condition)
+	if err != nil {
+		return "", err
+	}
+
+	var cons string
+	if node.Binding != "" {
+		cons, err = evalIfLetBlock(node, cond)
+	} else if cond != BOOL_TYPE {
+		return "", errors.New("condition not bool type")
+	} else {
+		cons, err = checker(node.Block)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if node.Alternative == nil {
+		return "", nil
+	}
+
+	alt, err := checker(node.Alternative)
+	if err != nil {
+		return "", err
+	}
+
+	if cons != NOTHING_TYPE && cons == alt {
+		return cons, nil
+	}
+	return "", nil
+}
+
+func evalForStatement(node *ast.ForStatement) (string, error) {
+	cond, err := checker(node.Condition)
+	if err != nil {
+		return "", err
+	}
+
 	if cond != BOOL_TYPE {
 		return "", errors.New("condition not bool type")
 	}
 
-	checker(node.Block)
-	checker(node.Alternative)
+	loopDepth++
+	_, err = checker(node.BlockStatement)
+	loopDepth--
+	if err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+func evalForRangeStatement(node *ast.ForRangeStatement) (string, error) {
+	bounds := []ast.Expression{node.Start, node.End}
+	if node.Step != nil {
+		bounds = append(bounds, node.Step)
+	}
+
+	for _, bound := range bounds {
+		kind, err := checker(bound)
+		if err != nil {
+			return "", err
+		}
+
+		if kind != INT_TYPE {
+			return "", errors.New("range bound not int type")
+		}
+	}
+
+	if step, ok := node.Step.(*ast.IntegerLiteral); ok && step.Value == "0" {
+		return "", errors.New("range step cannot be zero")
+	}
+
+	openScope()
+	defer closeScope()
+	env.Set(node.Iterator, INT_TYPE) // loop variable only lives in the loop
+
+	loopDepth++
+	_, err := checker(node.Block)
+	loopDepth--
+	if err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+// the value of an optional condition is only in scope of the block
+func evalIfLetBlock(node *ast.IfStatement, cond string) (string, error) {
+	elem, ok := OptionalElem(cond)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("if let needs an optional value, got %s", cond))
+	}
+	node.Type = cond // set type for code generation
+
+	openScope()
+	defer closeScope()
+	env.Set(node.Binding, elem)
+	return checker(node.Block)
+}
+
+func evalForInStatement(node *ast.ForInStatement) (string, error) {
+	iterable, err := checker(node.Iterable)
+	if err != nil {
+		return "", err
+	}
+
+	sig, ok := GetMethod(iterable, ITER)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("type %s is not iterable", iterable))
+	}
+	node.Type = sig.Return // set type for code generation
+
+	openScope()
+	defer closeScope()
+	env.Set(node.Iterator, sig.Return)
+
+	loopDepth++
+	_, err = checker(node.Block)
+	loopDepth--
+	if err != nil {
+		return "", err
+	}
+	return "", nil
+}
+
+func evalBreakStatement(node *ast.BreakStatement) (string, error) {
+	if loopDepth == 0 {
+		return "", fmt.Errorf("break outside of loop at line %d, column %d", node.Token.Pos.Line, node.Token.Pos.Column)
+	}
+	return "", nil
+}
+
+func evalContinueStatement(node *ast.ContinueStatement) (string, error) {
+	if loopDepth == 0 {
+		return "", fmt.Errorf("continue outside of loop at line %d, column %d", node.Token.Pos.Line, node.Token.Pos.Column)
+	}
 	return "", nil
 }
 
@@ -110,133 +374,1216 @@ func evalInitStatement(node *ast.InitStatement) (string, error) {
 		return "", errors.New("ident already exist")
 	}
 
-	right, err := checker(node.Expr)
+	if node.Declared != "" {
+		declared, err := resolveType(node.Declared)
+		if err != nil {
+			return "", err
+		}
+		node.Declared = declared
+	}
+
+	right, err := evalExpected(node.Expr, node.Declared)
 	if err != nil {
 		return "", err
 	}
 
+	if node.Declared != "" && right != node.Declared {
+		return "", errors.New("invalid type assignment")
+	}
+
+	node.Type = right             // set type for code generation
 	env.Set(node.Location, right) // set ident type
 	return "", nil
 }
 
-func evalAssignStatement(node *ast.AssignStatement) (string, error) {
-	right, err := checker(node.Right)
+func evalTupleInitStatement(node *ast.TupleInitStatement) (string, error) {
+	kind, err := checker(node.Expr)
 	if err != nil {
-		return "", nil
+		return "", err
 	}
 
-	if kind, ok := env.Get(node.Left.Value); ok {
-		if kind != right {
-			return "", errors.New("invalid type assignment")
+	elems, ok := TupleTypes(kind)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("cannot destructure type %s", kind))
+	}
+
+	if len(elems) != len(node.Locations) {
+		return "", errors.New(fmt.Sprintf("cannot destructure %s into %d values", kind, len(node.Locations)))
+	}
+
+	seen := map[string]bool{}
+	for _, location := range node.Locations {
+		if location == "_" {
+			continue
 		}
-	} else {
-		return "", errors.New("ident not exist")
+
+		if env.IdentExist(location) || seen[location] {
+			return "", errors.New("ident already exist")
+		}
+		seen[location] = true
 	}
+
+	for i, location := range node.Locations {
+		if location != "_" {
+			env.Set(location, elems[i])
+		}
+	}
+
+	node.Type = kind // set type for code generation
 	return "", nil
 }
 
-func evalFunctionStatement(node *ast.FunctionStatement) (string, error) {
-	var params []string
-	for _, param := range node.Parameters {
-		env.Set(param.Arg, param.Type) // set params into scope
-		params = append(params, param.Type)
+func evalAssignStatement(node *ast.AssignStatement) (string, error) {
+	kind, ok := env.Get(node.Left.Value)
+	if !ok {
+		return "", errors.New("ident not exist")
 	}
 
-	res, err := checker(node.Body)
-	if err != nil {
+	if err := checkNotCaptured(&node.Left); err != nil {
 		return "", err
 	}
-	// check if correct return type
-	if res != node.Return {
-		return "", errors.New("incorrect return type")
+
+	right, err := evalExpected(node.Right, kind)
+	if err != nil {
+		return "", err
 	}
 
-	SetFunctionSignature(node.Name, Signature{node.Return, params})
+	if kind != right {
+		return "", errors.New("invalid type assignment")
+	}
 	return "", nil
 }
 
-// Expressions
-
-func evalFunctionCall(node *ast.FunctionCall) (string, error) {
-	if IsBuiltin(node.Name) {
-		res, err := checker(node.Args[0])
-		if err != nil {
-			return "", err
-		}
-		node.Type = res
-		return NOTHING_TYPE, nil
+func evalIndexAssignStatement(node *ast.IndexAssignStatement) (string, error) {
+	if !isAssignable(node.Left) {
+		return "", errors.New("cannot assign to expression")
 	}
 
-	var sig Signature
-	var ok bool
-	if sig, ok = GetFunctionSignature(node.Name); !ok {
-		return "", errors.New("function not exist")
+	if err := checkNotCaptured(node.Left); err != nil {
+		return "", err
 	}
 
-	if len(node.Args) != len(sig.Params) {
-		return "", errors.New("incorrect amount of arguments to function")
+	kind, err := checker(node.Left)
+	if err != nil {
+		return "", err
 	}
 
-	// check params
-	for i, arg := range node.Args {
-		res, err := checker(arg)
-		if err != nil {
-			return "", errors.New(err.Error())
-		}
+	// a user INDEX returns a copy, only lists and maps hold their elements
+	container := node.Left.Type
+	if base, ok := env.Newtypes[container]; ok {
+		container = base
+	}
+	if !strings.HasPrefix(container, LIST_TYPE+"[") && !MethodExist(container, SET) {
+		return "", errors.New(fmt.Sprintf("cannot assign to index of type %s", node.Left.Type))
+	}
 
-		if res != sig.Params[i] {
-			return "", errors.New("incorrect argument type")
-		}
+	right, err := evalExpected(node.Right, kind)
+	if err != nil {
+		return "", err
 	}
 
-	return sig.Return, nil
+	if kind != right {
+		return "", errors.New("invalid type assignment")
+	}
+	return "", nil
 }
 
-func evalIdentifier(node *ast.Identifier) (string, error) {
-	kind, _ := env.Get(node.Value)
-	return kind, nil
+// only variables and what they contain can be assigned to
+func isAssignable(node ast.Expression) bool {
+	_, ok := rootVariable(node)
+	return ok
 }
 
-func evalBoolean(node *ast.Boolean) (string, error) {
-	return BOOL_TYPE, nil
+// the variable an expression like xs[0].f reads from
+func rootVariable(node ast.Expression) (string, bool) {
+	switch node := node.(type) {
+	case *ast.Identifier:
+		return node.Value, true
+	case *ast.IndexExpression:
+		return rootVariable(node.Left)
+	case *ast.FieldExpression:
+		return rootVariable(node.Left)
+	}
+	return "", false
 }
 
-func evalInteger(node *ast.IntegerLiteral) (string, error) {
-	return INT_TYPE, nil
+// anonymous functions hold copies of what they capture, so changing a
+// captured variable inside one would not be seen outside it
+func checkNotCaptured(node ast.Expression) error {
+	if name, ok := rootVariable(node); ok && env.Captured(name) {
+		return errors.New(fmt.Sprintf("cannot modify captured variable %s", name))
+	}
+	return nil
 }
 
-func evalString(node *ast.StringLiteral) (string, error) {
-	return STRING_TYPE, nil
-}
+func evalFieldAssignStatement(node *ast.FieldAssignStatement) (string, error) {
+	if !isAssignable(node.Left) {
+		return "", errors.New("cannot assign to expression")
+	}
 
-func evalInfixExpression(node *ast.InfixExpression) (string, error) {
-	left, err := checker(node.Left)
+	if err := checkNotCaptured(node.Left); err != nil {
+		return "", err
+	}
+
+	kind, err := checker(node.Left)
 	if err != nil {
-		return left, err
+		return "", err
 	}
 
-	right, err := checker(node.Right)
+	right, err := evalExpected(node.Right, kind)
 	if err != nil {
-		return right, err
+		return "", err
 	}
 
-	if left != right {
-		return "", errors.New("incorrect types for operation")
+	if kind != right {
+		return "", errors.New("invalid type assignment")
+	}
+	return "", nil
+}
+
+// fields may only use types declared before the struct
+func evalStructStatement(node *ast.StructStatement) (string, error) {
+	if err := checkTypeName(node.Name); err != nil {
+		return "", err
 	}
 
-	node.Type = left // set type for code generation
+	methods := Methods{PRINT: {NOTHING_TYPE, []string{}}}
+	seen := map[string]bool{}
+	var params []string
+	for i, field := range node.Fields {
+		if seen[field.Arg] {
+			return "", errors.New(fmt.Sprintf("duplicate field %s in %s", field.Arg, node.Name))
+		}
+		seen[field.Arg] = true
+
+		if _, ok := methods[field.Arg]; ok {
+			return "", errors.New(fmt.Sprintf("field %s conflicts with method of %s", field.Arg, node.Name))
+		}
+
+		kind, err := resolveType(field.Type)
+		if err != nil {
+			return "", err
+		}
+		node.Fields[i].Type = kind
+		params = append(params, kind)
+	}
+
+	env.Types[node.Name] = node.Name
+	env.Structs[node.Name] = node.Fields
+	TypeTable[node.Name] = methods
+	// constructed by calling the type with its fields in order
+	SetFunctionSignature(node.Name, Signature{node.Name, params})
+	return "", nil
+}
+
+// register a function or method signature, its body is checked later
+func declareFunction(node *ast.FunctionStatement) error {
+	if len(node.TypeParams) != 0 {
+		if err := declareTypeParams(node); err != nil {
+			return err
+		}
+		defer removeTypeParams(node)
+	}
+
+	if err := resolveSignature(node); err != nil {
+		return err
+	}
+
+	params := make([]string, len(node.Parameters))
+	for i, param := range node.Parameters {
+		params[i] = param.Type
+	}
+
+	if node.Receiver != nil {
+		if err := declareMethod(node, params); err != nil {
+			return err
+		}
+		SetMethodSignature(node.Receiver.Type, node.Name, Signature{node.Return, params})
+		return nil
+	}
+
+	if env.TypeExist(node.Name) || runtimeClasses[node.Name] {
+		return errors.New(fmt.Sprintf("function %s conflicts with type %s", node.Name, node.Name))
+	}
+
+	if _, ok := GetFunctionSignature(node.Name); ok || IsBuiltin(node.Name) {
+		return errors.New(fmt.Sprintf("function %s already declared", node.Name))
+	}
+
+	if runtimeFunctions[node.Name] {
+		return errors.New(fmt.Sprintf("function name %s is reserved", node.Name))
+	}
+
+	SetFunctionSignature(node.Name, Signature{node.Return, params})
+	if len(node.TypeParams) != 0 {
+		env.Generics[node.Name] = node.TypeParams
+	}
+	return nil
+}
 
-	methods := map[string]string{"+": PLUS, "-": MINUS, "==": EQUAL, "<": LT, ">": GT, "*": TIMES, "or": OR, "and": AND}
+func evalFunctionStatement(node *ast.FunctionStatement) (string, error) {
+	if len(node.TypeParams) != 0 {
+		if err := declareTypeParams(node); err != nil {
+			return "", err
+		}
+		defer removeTypeParams(node)
+		genericFunc = node
+		defer func() { genericFunc = nil }()
+	}
+
+	return "", evalFunctionBody(node)
+}
+
+// type parameters are opaque types within their function, with only
+// the methods their constraint requires
+func declareTypeParams(node *ast.FunctionStatement) error {
+	seen := map[string]bool{}
+	for i, param := range node.TypeParams {
+		if seen[param.Name] || env.TypeExist(param.Name) {
+			return errors.New(fmt.Sprintf("type parameter %s already declared", param.Name))
+		}
+		seen[param.Name] = true
 
-	if !MethodExist(left, methods[node.Operator]) {
-		return NOTHING_TYPE, errors.New(fmt.Sprintf("method %s not exist for type %s", methods[node.Operator], left))
+		if param.Constraint != "" {
+			constraint := expandType(param.Constraint)
+			if _, ok := env.Interfaces[constraint]; !ok {
+				return errors.New(fmt.Sprintf("constraint %s is not an interface", param.Constraint))
+			}
+			node.TypeParams[i].Constraint = constraint
+		}
+
+		// type arguments are only inferred from the arguments
+		used := false
+		for _, arg := range node.Parameters {
+			used = used || hasUnbound(arg.Type, map[string]string{param.Name: ""})
+		}
+		if !used {
+			return errors.New(fmt.Sprintf("type parameter %s of %s is not used by its parameters", param.Name, node.Name))
+		}
 	}
 
-	for _, opr := range []string{"<=", "<", ">=", ">", "or", "and"} {
-		if node.Operator == opr {
-			return BOOL_TYPE, nil
+	for _, param := range node.TypeParams {
+		env.Types[param.Name] = param.Name
+		TypeTable[param.Name] = Methods{}
+		if param.Constraint != "" {
+			TypeTable[param.Name] = constraintMethods(param.Name, param.Constraint)
 		}
 	}
+	return nil
+}
+
+func removeTypeParams(node *ast.FunctionStatement) {
+	for _, param := range node.TypeParams {
+		delete(env.Types, param.Name)
+		delete(TypeTable, param.Name)
+	}
+}
 
-	return left, nil
+func checkTypeName(name string) error {
+	if env.TypeExist(name) || runtimeClasses[name] {
+		return errors.New(fmt.Sprintf("type %s already declared", name))
+	}
+	return nil
+}
+
+// the generated class of an enum keeps its tag and one member
+// for each payload value of each variant
+func enumMember(variants []ast.Variant, name string) bool {
+	if name == "tag" {
+		return true
+	}
+	for _, variant := range variants {
+		for i := range variant.Types {
+			if name == fmt.Sprintf("%s_%d", variant.Name, i) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// an alias is interchangeable with the type it stands for, a newtype
+// is a distinct type with the methods of its base
+func evalTypeStatement(node *ast.TypeStatement) (string, error) {
+	if err := checkTypeName(node.Name); err != nil {
+		return "", err
+	}
+
+	base, err := resolveType(node.Type)
+	if err != nil {
+		return "", err
+	}
+	node.Type = base
+
+	if node.Alias {
+		env.Types[node.Name] = base
+		return "", nil
+	}
+
+	if base == NOTHING_TYPE {
+		return "", errors.New(fmt.Sprintf("cannot declare type %s over %s", node.Name, base))
+	}
+
+	env.Types[node.Name] = node.Name
+	env.Newtypes[node.Name] = base
+	TypeTable[node.Name] = Methods{}
+	return "", nil
+}
+
+// an interface lists methods, any type with all of them can be used
+// where the interface is expected, every interface can be printed
+func evalInterfaceStatement(node *ast.InterfaceStatement) (string, error) {
+	if err := checkTypeName(node.Name); err != nil {
+		return "", err
+	}
+	env.Types[node.Name] = node.Name // methods may take or return the interface
+
+	methods := Methods{PRINT: {NOTHING_TYPE, []string{}}}
+	for i, spec := range node.Methods {
+		if _, ok := methods[spec.Name]; ok {
+			return "", errors.New(fmt.Sprintf("duplicate method %s in %s", spec.Name, node.Name))
+		}
+
+		ret, err := resolveType(spec.Return)
+		if err != nil {
+			return "", err
+		}
+		node.Methods[i].Return = ret
+
+		var params []string
+		for j, param := range spec.Parameters {
+			kind, err := resolveType(param.Type)
+			if err != nil {
+				return "", err
+			}
+			spec.Parameters[j].Type = kind
+			params = append(params, kind)
+		}
+
+		if err := checkOperatorMethod(node.Name, spec.Name, params, ret); err != nil {
+			return "", err
+		}
+		methods[spec.Name] = Signature{ret, params}
+	}
+
+	env.Interfaces[node.Name] = node.Methods
+	TypeTable[node.Name] = methods
+	return "", nil
+}
+
+// variant payloads may only use types declared before the enum,
+// an enum has equality when all of its payloads do
+func evalEnumStatement(node *ast.EnumStatement) (string, error) {
+	if err := checkTypeName(node.Name); err != nil {
+		return "", err
+	}
+
+	methods := Methods{PRINT: {NOTHING_TYPE, []string{}}}
+	equality := true
+	seen := map[string]bool{}
+	for _, variant := range node.Variants {
+		if seen[variant.Name] {
+			return "", errors.New(fmt.Sprintf("duplicate variant %s in %s", variant.Name, node.Name))
+		}
+		seen[variant.Name] = true
+
+		for i, kind := range variant.Types {
+			kind, err := resolveType(kind)
+			if err != nil {
+				return "", err
+			}
+			variant.Types[i] = kind
+			equality = equality && MethodExist(kind, EQUAL)
+		}
+	}
+
+	if equality {
+		methods[EQUAL] = Signature{BOOL_TYPE, []string{node.Name}}
+		methods[NEQUAL] = Signature{BOOL_TYPE, []string{node.Name}}
+	}
+
+	for _, variant := range node.Variants {
+		if _, ok := methods[variant.Name]; ok {
+			return "", errors.New(fmt.Sprintf("variant %s conflicts with method of %s", variant.Name, node.Name))
+		}
+		if enumMember(node.Variants, variant.Name) {
+			return "", errors.New(fmt.Sprintf("variant %s conflicts with a member of %s", variant.Name, node.Name))
+		}
+	}
+
+	env.Types[node.Name] = node.Name
+	env.Enums[node.Name] = node.Variants
+	TypeTable[node.Name] = methods
+	return "", nil
+}
+
+// methods can only be declared on structs, enums and newtypes and are added to their type's methods
+func declareMethod(node *ast.FunctionStatement, params []string) error {
+	kind := node.Receiver.Type
+	if !isUserType(kind) {
+		return errors.New(fmt.Sprintf("cannot declare method %s on type %s", node.Name, kind))
+	}
+
+	if MethodExist(kind, node.Name) {
+		return errors.New(fmt.Sprintf("method %s already declared for type %s", node.Name, kind))
+	}
+
+	if _, ok := FieldType(kind, node.Name); ok {
+		return errors.New(fmt.Sprintf("method %s conflicts with field of type %s", node.Name, kind))
+	}
+
+	if _, _, ok := LookupVariant(kind, node.Name); ok {
+		return errors.New(fmt.Sprintf("method %s conflicts with variant of type %s", node.Name, kind))
+	}
+
+	if variants, ok := env.Enums[kind]; ok && enumMember(variants, node.Name) {
+		return errors.New(fmt.Sprintf("method %s conflicts with a member of type %s", node.Name, kind))
+	}
+
+	return checkOperatorMethod(kind, node.Name, params, node.Return)
+}
+
+// methods named after an operator must fit how the operator is used,
+// ITER and SET hand the runtime's containers to for loops and index
+// assignment so they are left to the builtin types
+func checkOperatorMethod(kind, name string, params []string, ret string) error {
+	switch name {
+	case NEG, NOT:
+		if len(params) != 0 {
+			return errors.New(fmt.Sprintf("operator method %s takes no arguments", name))
+		}
+	case PLUS, MINUS, TIMES, DIVIDE, MOD, AND, OR:
+		if len(params) != 1 {
+			return errors.New(fmt.Sprintf("operator method %s takes one argument", name))
+		}
+	case EQUAL, NEQUAL, LT, GT, LE, GE:
+		if len(params) != 1 || params[0] != kind || ret != BOOL_TYPE {
+			return errors.New(fmt.Sprintf("operator method %s must take a %s and return Bool", name, kind))
+		}
+	case INDEX:
+		if len(params) != 1 || ret == NOTHING_TYPE {
+			return errors.New(fmt.Sprintf("operator method %s must take one argument and return a value", name))
+		}
+	case ITER, SET:
+		return errors.New(fmt.Sprintf("method name %s is reserved", name))
+	}
+	return nil
+}
+
+// replace the aliases in a function's signature with the types they stand for
+func resolveSignature(node *ast.FunctionStatement) error {
+	var err error
+	if node.Return, err = resolveType(node.Return); err != nil {
+		return err
+	}
+
+	if node.Receiver != nil {
+		if node.Receiver.Type, err = resolveType(node.Receiver.Type); err != nil {
+			return err
+		}
+	}
+
+	for i := range node.Parameters {
+		if node.Parameters[i].Type, err = resolveType(node.Parameters[i].Type); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// check a function's body in its own scope against its signature
+func evalFunctionBody(node *ast.FunctionStatement) error {
+	openScope()
+	defer closeScope()
+
+	if node.Receiver != nil {
+		env.Set(node.Receiver.Arg, node.Receiver.Type)
+	}
+
+	for _, param := range node.Parameters {
+		env.Set(param.Arg, param.Type) // set params into scope
+	}
+
+	outer := returnType
+	returnType = node.Return
+	res, err := checker(node.Body)
+	returnType = outer
+	if err != nil {
+		return err
+	}
+	// check if correct return type
+	if res != node.Return {
+		return errors.New("incorrect return type")
+	}
+	return nil
+}
+
+// Expressions
+
+func evalFunctionCall(node *ast.FunctionCall) (string, error) {
+	if IsMethodCall(node) {
+		return evalMethodCall(node)
+	}
+
+	// variables hide functions of the same name
+	if kind, ok := env.Get(node.Name); ok {
+		node.Value, node.Type = true, kind // set for code generation
+		return evalValueCall(kind, node.Args)
+	}
+
+	var sig Signature
+	var ok bool
+	if sig, ok = GetFunctionSignature(node.Name); !ok {
+		if env.TypeExist(node.Name) {
+			return evalConversion(node)
+		}
+		return "", errors.New("function not exist")
+	}
+
+	if params, ok := env.Generics[node.Name]; ok {
+		return evalGenericCall(node, sig, params)
+	}
+
+	if err := evalArgs(node.Args, sig.Params); err != nil {
+		return "", err
+	}
+	return sig.Return, nil
+}
+
+func evalArgs(args []ast.Expression, params []string) error {
+	if len(args) != len(params) {
+		return errors.New("incorrect amount of arguments to function")
+	}
+
+	for i, arg := range args {
+		res, err := evalExpected(arg, params[i])
+		if err != nil {
+			return err
+		}
+
+		if res != params[i] {
+			return errors.New("incorrect argument type")
+		}
+	}
+	return nil
+}
+
+// an anonymous function is checked like a function body, in a scope
+// that records the outside variables it reads as its captures
+func evalFunctionLiteral(node *ast.FunctionLiteral) (string, error) {
+	var err error
+	if node.Return, err = resolveType(node.Return); err != nil {
+		return "", err
+	}
+
+	params := make([]string, len(node.Parameters))
+	for i := range node.Parameters {
+		if node.Parameters[i].Type, err = resolveType(node.Parameters[i].Type); err != nil {
+			return "", err
+		}
+		params[i] = node.Parameters[i].Type
+	}
+
+	openScope()
+	defer closeScope()
+	env.Lambda = node
+	node.Captures = []string{}
+	for _, param := range node.Parameters {
+		env.Set(param.Arg, param.Type)
+	}
+
+	// loops outside do not continue into the body
+	outerReturn, outerDepth := returnType, loopDepth
+	returnType, loopDepth = node.Return, 0
+	res, err := checker(node.Body)
+	returnType, loopDepth = outerReturn, outerDepth
+	if err != nil {
+		return "", err
+	}
+
+	if res != node.Return {
+		return "", errors.New("incorrect return type")
+	}
+
+	node.Type = FuncOf(params, node.Return) // set type for code generation
+	return node.Type, nil
+}
+
+// a call through a value of function type
+func evalValueCall(kind string, args []ast.Expression) (string, error) {
+	params, ret, ok := FuncTypes(kind)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("type %s is not a function", kind))
+	}
+
+	if err := evalArgs(args, params); err != nil {
+		return "", err
+	}
+	return ret, nil
+}
+
+func evalCallExpression(node *ast.CallExpression) (string, error) {
+	kind, err := checker(node.Function)
+	if err != nil {
+		return "", err
+	}
+
+	node.Type = kind // set type for code generation
+	return evalValueCall(kind, node.Args)
+}
+
+// type arguments are inferred from the arguments in order, a parameter
+// whose type is already known is checked like any other argument
+func evalGenericCall(node *ast.FunctionCall, sig Signature, params []ast.TypeParam) (string, error) {
+	if len(node.Args) != len(sig.Params) {
+		return "", errors.New("incorrect amount of arguments to function")
+	}
+
+	bindings := map[string]string{}
+	for _, param := range params {
+		bindings[param.Name] = ""
+	}
+
+	for i, arg := range node.Args {
+		if !hasUnbound(sig.Params[i], bindings) {
+			want := SubstituteType(sig.Params[i], bindings)
+			res, err := evalExpected(arg, want)
+			if err != nil {
+				return "", err
+			}
+
+			if res != want {
+				return "", errors.New("incorrect argument type")
+			}
+			continue
+		}
+
+		res, err := checker(arg)
+		if err != nil {
+			return "", err
+		}
+
+		if !unify(sig.Params[i], res, bindings) {
+			return "", errors.New("incorrect argument type")
+		}
+	}
+
+	node.TypeArgs = make([]string, len(params)) // set for code generation
+	for i, param := range params {
+		kind := bindings[param.Name]
+		if param.Constraint != "" && !meetsConstraint(kind, param.Constraint) {
+			return "", errors.New(fmt.Sprintf("type %s does not satisfy %s", kind, param.Constraint))
+		}
+		node.TypeArgs[i] = kind
+	}
+
+	if genericFunc != nil {
+		for i, param := range params {
+			for _, outer := range genericFunc.TypeParams {
+				if hasUnbound(node.TypeArgs[i], map[string]string{outer.Name: ""}) {
+					typeArgEdges = append(typeArgEdges, typeArgEdge{genericFunc.Name + "." + outer.Name,
+						node.Name + "." + param.Name, node.TypeArgs[i] != outer.Name})
+				}
+			}
+		}
+	}
+	return SubstituteType(sig.Return, bindings), nil
+}
+
+// generic functions are instantiated for every list of type arguments
+// they are called with, so a cycle of calls that keeps wrapping a type
+// parameter in a larger type would need instances without end
+func checkInstantiation() error {
+	next := map[string][]string{}
+	for _, edge := range typeArgEdges {
+		next[edge.from] = append(next[edge.from], edge.to)
+	}
+
+	for _, edge := range typeArgEdges {
+		if edge.grows && reaches(next, edge.to, edge.from, map[string]bool{}) {
+			name := strings.Split(edge.from, ".")[0]
+			return errors.New(fmt.Sprintf("generic function %s recursively calls itself with a growing type argument", name))
+		}
+	}
+	return nil
+}
+
+func reaches(next map[string][]string, from, to string, seen map[string]bool) bool {
+	if from == to {
+		return true
+	}
+	if seen[from] {
+		return false
+	}
+	seen[from] = true
+
+	for _, n := range next[from] {
+		if reaches(next, n, to, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// calling a type converts between a newtype and its base,
+// calling an alias of a struct constructs the struct
+func evalConversion(node *ast.FunctionCall) (string, error) {
+	target := env.Types[node.Name]
+	if _, ok := env.Structs[target]; ok {
+		node.Name = target
+		return evalFunctionCall(node)
+	}
+
+	if len(node.Args) != 1 {
+		return "", errors.New("incorrect amount of arguments to function")
+	}
+
+	base := env.Newtypes[target]
+	from, err := evalExpected(node.Args[0], base)
+	if err != nil {
+		return "", err
+	}
+
+	if from == "" || (from != target && from != base && env.Newtypes[from] != target) {
+		return "", errors.New(fmt.Sprintf("cannot convert %s to %s", from, target))
+	}
+
+	node.Type = target // set type for code generation
+	return target, nil
+}
+
+// builtins and methods are checked as a method of the first argument's type
+func evalMethodCall(node *ast.FunctionCall) (string, error) {
+	if len(node.Args) == 0 {
+		return "", errors.New("incorrect amount of arguments to function")
+	}
+
+	// only reached through xs[i], for loops and index assignment
+	if node.Name == INDEX || node.Name == ITER || node.Name == SET {
+		return "", errors.New(fmt.Sprintf("method %s cannot be called directly", node.Name))
+	}
+
+	res, err := checker(node.Args[0])
+	if err != nil {
+		return "", err
+	}
+	node.Type = res
+
+	if node.Name == APPEND || node.Name == DELETE {
+		if err := checkNotCaptured(node.Args[0]); err != nil {
+			return "", err
+		}
+	}
+
+	sig, ok := GetMethod(res, node.Name)
+	if !ok {
+		// p.f(x) also calls a struct field holding a function
+		if field, ok := FieldType(res, node.Name); ok {
+			return evalValueCall(field, node.Args[1:])
+		}
+		return "", errors.New(fmt.Sprintf("method %s not exist for type %s", node.Name, res))
+	}
+
+	if err := evalArgs(node.Args[1:], sig.Params); err != nil {
+		return "", err
+	}
+	return sig.Return, nil
+}
+
+// a function name not hidden by a variable is a value of function type
+func evalIdentifier(node *ast.Identifier) (string, error) {
+	if kind, ok := env.Get(node.Value); ok {
+		return kind, nil
+	}
+
+	sig, ok := GetFunctionSignature(node.Value)
+	if !ok {
+		return "", errors.New("ident not exist")
+	}
+
+	if _, ok := env.Structs[node.Value]; ok {
+		return "", errors.New(fmt.Sprintf("cannot use constructor %s as a value", node.Value))
+	}
+
+	if _, ok := env.Generics[node.Value]; ok {
+		return "", errors.New(fmt.Sprintf("cannot use generic function %s as a value", node.Value))
+	}
+	node.Function = true // set for code generation
+	return FuncOf(sig.Params, sig.Return), nil
+}
+
+func evalBoolean(node *ast.Boolean) (string, error) {
+	return BOOL_TYPE, nil
+}
+
+func evalInteger(node *ast.IntegerLiteral) (string, error) {
+	return INT_TYPE, nil
+}
+
+func evalFloat(node *ast.FloatLiteral) (string, error) {
+	return FLOAT_TYPE, nil
+}
+
+func evalString(node *ast.StringLiteral) (string, error) {
+	return STRING_TYPE, nil
+}
+
+func evalInfixExpression(node *ast.InfixExpression) (string, error) {
+	left, err := checker(node.Left)
+	if err != nil {
+		return left, err
+	}
+
+	node.Type = left // set type for code generation
+
+	sig, ok := GetMethod(left, Operators[node.Operator])
+	if !ok {
+		return NOTHING_TYPE, errors.New(fmt.Sprintf("method %s not exist for type %s", Operators[node.Operator], left))
+	}
+
+	// the right side is the operator method's argument
+	right, err := evalExpected(node.Right, sig.Params[0])
+	if err != nil {
+		return right, err
+	}
+
+	if right != sig.Params[0] {
+		return "", errors.New("incorrect types for operation")
+	}
+
+	return sig.Return, nil
+}
+
+func evalPrefixExpression(node *ast.PrefixExpression) (string, error) {
+	right, err := checker(node.Right)
+	if err != nil {
+		return right, err
+	}
+
+	node.Type = right // set type for code generation
+
+	sig, ok := GetMethod(right, PrefixOperators[node.Operator])
+	if !ok {
+		return NOTHING_TYPE, errors.New(fmt.Sprintf("method %s not exist for type %s", PrefixOperators[node.Operator], right))
+	}
+
+	return sig.Return, nil
+}
+
+// check an expression where the context expects a type, letting
+// literals that cannot name their own type, like [], take it on
+func evalExpected(node ast.Expression, want string) (string, error) {
+	if list, ok := node.(*ast.ListLiteral); ok {
+		if elem, ok := ListElem(want); ok {
+			return evalListLiteral(list, elem)
+		}
+	}
+
+	if tuple, ok := node.(*ast.TupleLiteral); ok {
+		if elems, ok := TupleTypes(want); ok && len(elems) == len(tuple.Elements) {
+			return evalTupleLiteral(tuple, elems)
+		}
+	}
+
+	if m, ok := node.(*ast.MapLiteral); ok {
+		if key, value, ok := MapTypes(want); ok {
+			return evalMapLiteral(m, key, value)
+		}
+	}
+
+	elem, optional := OptionalElem(want)
+	if none, ok := node.(*ast.NoneLiteral); ok && optional {
+		none.Type = want // set type for code generation
+		return want, checkType(want)
+	}
+
+	if optional {
+		// a plain value is wrapped where an optional is expected
+		res, err := evalExpected(node, elem)
+		if err == nil && res == elem {
+			return want, nil
+		}
+		return res, err
+	}
+
+	if _, ok := env.Interfaces[want]; ok {
+		// a value is boxed where an interface it satisfies is expected
+		res, err := checker(node)
+		if err == nil && satisfies(res, want) {
+			return want, nil
+		}
+		return res, err
+	}
+	return checker(node)
+}
+
+// elements are checked against want when the context gives the tuple type
+func evalTupleLiteral(node *ast.TupleLiteral, want []string) (string, error) {
+	elems := make([]string, len(node.Elements))
+	for i, elem := range node.Elements {
+		expected := ""
+		if want != nil {
+			expected = want[i]
+		}
+
+		kind, err := evalExpected(elem, expected)
+		if err != nil {
+			return "", err
+		}
+
+		if kind == NOTHING_TYPE {
+			return "", errors.New("tuple element has no value")
+		}
+		elems[i] = kind
+	}
+
+	node.Type = TupleOf(elems) // set type for code generation
+	return node.Type, nil
+}
+
+// elements are checked against elem when the context gives the list type,
+// otherwise the first element decides it
+func evalListLiteral(node *ast.ListLiteral, elem string) (string, error) {
+	if len(node.Elements) == 0 && elem == "" {
+		return "", errors.New("cannot infer type of empty list")
+	}
+
+	for i, e := range node.Elements {
+		kind, err := evalExpected(e, elem)
+		if err != nil {
+			return "", err
+		}
+
+		if i == 0 && elem == "" {
+			elem = kind
+		}
+
+		if kind != elem {
+			return "", errors.New("incorrect list element type")
+		}
+	}
+
+	node.Type = ListOf(elem) // set type for code generation
+	return node.Type, nil
+}
+
+func evalIndexExpression(node *ast.IndexExpression) (string, error) {
+	left, err := checker(node.Left)
+	if err != nil {
+		return "", err
+	}
+
+	sig, ok := GetMethod(left, INDEX)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("type %s cannot be indexed", left))
+	}
+
+	index, err := evalExpected(node.Index, sig.Params[0])
+	if err != nil {
+		return "", err
+	}
+
+	if index != sig.Params[0] {
+		return "", errors.New("incorrect index type")
+	}
+
+	node.Type = left // set type for code generation
+	return sig.Return, nil
+}
+
+// like list literals, the context or the first entry decides the types
+func evalMapLiteral(node *ast.MapLiteral, key, value string) (string, error) {
+	if len(node.Entries) == 0 && key == "" {
+		return "", errors.New("cannot infer type of empty map")
+	}
+
+	for i, entry := range node.Entries {
+		k, err := evalExpected(entry.Key, key)
+		if err != nil {
+			return "", err
+		}
+
+		v, err := evalExpected(entry.Value, value)
+		if err != nil {
+			return "", err
+		}
+
+		if i == 0 && key == "" {
+			key, value = k, v
+		}
+
+		if k != key || v != value {
+			return "", errors.New("incorrect map entry type")
+		}
+	}
+
+	node.Type = MapOf(key, value) // set type for code generation
+	return node.Type, checkType(node.Type)
+}
+
+func evalFieldExpression(node *ast.FieldExpression) (string, error) {
+	left, err := checker(node.Left)
+	if err != nil {
+		return "", err
+	}
+
+	kind, ok := FieldType(left, node.Field)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("type %s has no field %s", left, node.Field))
+	}
+
+	node.Type = left // set type for code generation
+	return kind, nil
+}
+
+func evalVariantExpression(node *ast.VariantExpression) (string, error) {
+	if _, ok := env.Enums[node.Enum]; !ok {
+		return "", errors.New(fmt.Sprintf("enum %s not exist", node.Enum))
+	}
+
+	variant, _, ok := LookupVariant(node.Enum, node.Variant)
+	if !ok {
+		return "", errors.New(fmt.Sprintf("enum %s has no variant %s", node.Enum, node.Variant))
+	}
+
+	if len(node.Args) != len(variant.Types) {
+		return "", errors.New(fmt.Sprintf("variant %s::%s takes %d values", node.Enum, node.Variant, len(variant.Types)))
+	}
+
+	for i, arg := range node.Args {
+		res, err := evalExpected(arg, variant.Types[i])
+		if err != nil {
+			return "", err
+		}
+
+		if res != variant.Types[i] {
+			return "", errors.New("incorrect variant value type")
+		}
+	}
+	return node.Enum, nil
+}
+
+func evalMatchStatement(node *ast.MatchStatement) (string, error) {
+	kind, err := evalMatchValue(node.Value, node.Arms)
+	if err != nil {
+		return "", err
+	}
+	node.Type = kind // set type for code generation
+
+	// like an if statement, a match returns when all of its arms do
+	result := ""
+	for i, arm := range node.Arms {
+		res, err := evalMatchArm(kind, arm, func() (string, error) {
+			return checker(arm.Block)
+		})
+		if err != nil {
+			return "", err
+		}
+
+		if i == 0 {
+			result = res
+		} else if res != result {
+			result = ""
+		}
+	}
+
+	if result == NOTHING_TYPE {
+		return "", nil
+	}
+	return result, nil
+}
+
+func evalMatchExpression(node *ast.MatchExpression) (string, error) {
+	kind, err := evalMatchValue(node.Value, node.Arms)
+	if err != nil {
+		return "", err
+	}
+	node.Type = kind // set type for code generation
+
+	// the first arm decides the type of the match
+	for i, arm := range node.Arms {
+		res, err := evalMatchArm(kind, arm, func() (string, error) {
+			return evalExpected(arm.Value, node.Result)
+		})
+		if err != nil {
+			return "", err
+		}
+
+		if i == 0 {
+			node.Result = res
+		} else if res != node.Result {
+			return "", errors.New("match arms have different types")
+		}
+	}
+	return node.Result, nil
+}
+
+// type of the matched value, arms must cover every variant once
+func evalMatchValue(value ast.Expression, arms []ast.MatchArm) (string, error) {
+	kind, err := checker(value)
+	if err != nil {
+		return "", err
+	}
+
+	variants, ok := env.Enums[kind]
+	if !ok {
+		return "", errors.New(fmt.Sprintf("cannot match on type %s", kind))
+	}
+
+	covered := map[string]bool{}
+	for i, arm := range arms {
+		pattern := arm.Pattern
+		if pattern.Variant == "_" {
+			if i != len(arms)-1 {
+				return "", errors.New("wildcard must be the last match arm")
+			}
+			return kind, nil
+		}
+
+		if pattern.Enum != kind {
+			return "", errors.New(fmt.Sprintf("pattern %s::%s does not match type %s", pattern.Enum, pattern.Variant, kind))
+		}
+
+		if _, _, ok := LookupVariant(kind, pattern.Variant); !ok {
+			return "", errors.New(fmt.Sprintf("enum %s has no variant %s", kind, pattern.Variant))
+		}
+
+		if covered[pattern.Variant] {
+			return "", errors.New(fmt.Sprintf("variant %s::%s matched more than once", kind, pattern.Variant))
+		}
+		covered[pattern.Variant] = true
+	}
+
+	for _, variant := range variants {
+		if !covered[variant.Name] {
+			return "", errors.New(fmt.Sprintf("match on %s is missing variant %s", kind, variant.Name))
+		}
+	}
+	return kind, nil
+}
+
+// check an arm with its bindings in scope, _ binds nothing
+func evalMatchArm(kind string, arm ast.MatchArm, body func() (string, error)) (string, error) {
+	openScope()
+	defer closeScope()
+
+	pattern := arm.Pattern
+	if pattern.Variant != "_" {
+		variant, _, _ := LookupVariant(kind, pattern.Variant)
+		if len(pattern.Bindings) != len(variant.Types) {
+			return "", errors.New(fmt.Sprintf("variant %s::%s has %d values", kind, pattern.Variant, len(variant.Types)))
+		}
+
+		for i, name := range pattern.Bindings {
+			if name == "_" {
+				continue
+			}
+
+			if env.IdentExist(name) {
+				return "", errors.New(fmt.Sprintf("%s bound more than once", name))
+			}
+			env.Set(name, variant.Types[i])
+		}
+	}
+	return body()
 }
@@ -1,26 +1,63 @@
 package checker
 
+import "github.com/Lebonesco/go-compiler/ast"
+
 // operations
 const (
-	PLUS  = "PLUS"
-	EQUAL = "EQ"
-	LT    = "LT"
-	GT    = "GT"
-	MINUS = "MINUS"
-	TIMES = "TIMES"
-	AND   = "AND"
-	OR    = "OR"
-	PRINT = "PRINT"
+	PLUS    = "PLUS"
+	EQUAL   = "EQ"
+	NEQUAL  = "NE"
+	LT      = "LT"
+	GT      = "GT"
+	LE      = "LE"
+	GE      = "GE"
+	MINUS   = "MINUS"
+	TIMES   = "TIMES"
+	DIVIDE  = "DIV"
+	MOD     = "MOD"
+	AND     = "AND"
+	OR      = "OR"
+	NEG     = "NEG"
+	NOT     = "NOT"
+	PRINT   = "PRINT"
+	TOINT   = "INT"
+	TOFLOAT = "FLOAT"
+	LEN     = "LEN"
+	APPEND  = "APPEND"
+	INDEX   = "INDEX" // xs[i]
+	ITER    = "ITER"  // for x in xs
+	SET     = "SET"   // m[k] = v
+	HAS     = "HAS"
+	DELETE  = "DELETE"
 )
 
+// map infix operators to type methods
+var Operators = map[string]string{
+	"+": PLUS, "-": MINUS, "*": TIMES, "/": DIVIDE, "%": MOD,
+	"==": EQUAL, "!=": NEQUAL, "<": LT, ">": GT, "<=": LE, ">=": GE,
+	"and": AND, "or": OR}
+
+// map prefix operators to type methods
+var PrefixOperators = map[string]string{"-": NEG, "not": NOT}
+
 // variable types
 const (
 	INT_TYPE     = "Int"
+	FLOAT_TYPE   = "Float"
 	STRING_TYPE  = "String"
 	BOOL_TYPE    = "Bool"
 	NOTHING_TYPE = "Nothing"
+	LIST_TYPE    = "List"
+	MAP_TYPE     = "Map"
 )
 
+// classes the C++ runtime defines besides the builtin types
+var runtimeClasses = map[string]bool{LIST_TYPE: true, MAP_TYPE: true, "Tuple": true, "Optional": true, "Base": true}
+
+// functions the C++ runtime defines that user functions would clash with
+var runtimeFunctions = map[string]bool{"main": true, "show": true, "showValue": true, "formatFloat": true,
+	"runtimeError": true}
+
 type Signature struct {
 	Return string
 	Params []string // list of types
@@ -28,42 +65,121 @@ type Signature struct {
 
 type Methods map[string]Signature
 
-// type methods
-var TypeTable = map[string]Methods{
-	INT_TYPE: {
-		PLUS:  {INT_TYPE, []string{INT_TYPE}},
-		MINUS: {INT_TYPE, []string{INT_TYPE}},
-		TIMES: {INT_TYPE, []string{INT_TYPE}},
-		LT:    {BOOL_TYPE, []string{INT_TYPE}},
-		GT:    {BOOL_TYPE, []string{INT_TYPE}},
-		EQUAL: {BOOL_TYPE, []string{INT_TYPE}},
-		PRINT: {NOTHING_TYPE, []string{}}},
-	STRING_TYPE: {
-		PLUS:  {STRING_TYPE, []string{STRING_TYPE}},
-		PRINT: {NOTHING_TYPE, []string{}}},
-	BOOL_TYPE: {
-		AND:   {BOOL_TYPE, []string{BOOL_TYPE}},
-		OR:    {BOOL_TYPE, []string{BOOL_TYPE}},
-		PRINT: {NOTHING_TYPE, []string{}}}}
+// type methods, user types add their methods as they are declared
+var TypeTable = builtinTypeTable()
+
+func builtinTypeTable() map[string]Methods {
+	return map[string]Methods{
+		INT_TYPE: {
+			PLUS:    {INT_TYPE, []string{INT_TYPE}},
+			MINUS:   {INT_TYPE, []string{INT_TYPE}},
+			TIMES:   {INT_TYPE, []string{INT_TYPE}},
+			DIVIDE:  {INT_TYPE, []string{INT_TYPE}},
+			MOD:     {INT_TYPE, []string{INT_TYPE}},
+			LT:      {BOOL_TYPE, []string{INT_TYPE}},
+			GT:      {BOOL_TYPE, []string{INT_TYPE}},
+			LE:      {BOOL_TYPE, []string{INT_TYPE}},
+			GE:      {BOOL_TYPE, []string{INT_TYPE}},
+			EQUAL:   {BOOL_TYPE, []string{INT_TYPE}},
+			NEQUAL:  {BOOL_TYPE, []string{INT_TYPE}},
+			NEG:     {INT_TYPE, []string{}},
+			TOFLOAT: {FLOAT_TYPE, []string{}},
+			PRINT:   {NOTHING_TYPE, []string{}}},
+		FLOAT_TYPE: {
+			PLUS:   {FLOAT_TYPE, []string{FLOAT_TYPE}},
+			MINUS:  {FLOAT_TYPE, []string{FLOAT_TYPE}},
+			TIMES:  {FLOAT_TYPE, []string{FLOAT_TYPE}},
+			DIVIDE: {FLOAT_TYPE, []string{FLOAT_TYPE}},
+			LT:     {BOOL_TYPE, []string{FLOAT_TYPE}},
+			GT:     {BOOL_TYPE, []string{FLOAT_TYPE}},
+			LE:     {BOOL_TYPE, []string{FLOAT_TYPE}},
+			GE:     {BOOL_TYPE, []string{FLOAT_TYPE}},
+			EQUAL:  {BOOL_TYPE, []string{FLOAT_TYPE}},
+			NEQUAL: {BOOL_TYPE, []string{FLOAT_TYPE}},
+			NEG:    {FLOAT_TYPE, []string{}},
+			TOINT:  {INT_TYPE, []string{}},
+			PRINT:  {NOTHING_TYPE, []string{}}},
+		STRING_TYPE: {
+			PLUS:   {STRING_TYPE, []string{STRING_TYPE}},
+			EQUAL:  {BOOL_TYPE, []string{STRING_TYPE}},
+			NEQUAL: {BOOL_TYPE, []string{STRING_TYPE}},
+			LT:     {BOOL_TYPE, []string{STRING_TYPE}},
+			GT:     {BOOL_TYPE, []string{STRING_TYPE}},
+			LE:     {BOOL_TYPE, []string{STRING_TYPE}},
+			GE:     {BOOL_TYPE, []string{STRING_TYPE}},
+			PRINT:  {NOTHING_TYPE, []string{}}},
+		BOOL_TYPE: {
+			AND:    {BOOL_TYPE, []string{BOOL_TYPE}},
+			OR:     {BOOL_TYPE, []string{BOOL_TYPE}},
+			NOT:    {BOOL_TYPE, []string{}},
+			EQUAL:  {BOOL_TYPE, []string{BOOL_TYPE}},
+			NEQUAL: {BOOL_TYPE, []string{BOOL_TYPE}},
+			PRINT:  {NOTHING_TYPE, []string{}}}}
+}
 
 type Environment struct {
-	Vals  map[string]string    // map identifier to type
-	Funcs map[string]Signature // map function name to return type
-	Types map[string]bool      // track valid types
+	Vals       map[string]string           // map identifier to type
+	Funcs      map[string]Signature        // map function name to return type
+	Types      map[string]string           // map type name to the type it stands for
+	Newtypes   map[string]string           // map newtype to its base type
+	Structs    map[string][]ast.FormalArg  // struct fields in declared order
+	Enums      map[string][]ast.Variant    // enum variants in declared order
+	Interfaces map[string][]ast.MethodSpec // methods an interface requires
+	Generics   map[string][]ast.TypeParam  // type parameters of generic functions
+	Lambda     *ast.FunctionLiteral        // set on the parameter scope of an anonymous function
+	Outer      *Environment                // enclosing scope
 }
 
-var env Environment // set global
+var env *Environment // set global
+
+// builtin functions are methods called on their first argument
+var Builtins = map[string]bool{PRINT: true, TOINT: true, TOFLOAT: true, LEN: true, APPEND: true,
+	HAS: true, DELETE: true}
 
 func IsBuiltin(name string) bool {
-	return name == "PRINT"
+	return Builtins[name]
 }
 
-func NewEnvironment() Environment {
-	return Environment{Vals: map[string]string{}, Funcs: map[string]Signature{}, Types: map[string]bool{}}
+// builtins and x.method(...) calls are dispatched on their first argument
+func IsMethodCall(node *ast.FunctionCall) bool {
+	return node.Method || IsBuiltin(node.Name)
+}
+
+func NewEnvironment() *Environment {
+	types := map[string]string{INT_TYPE: INT_TYPE, FLOAT_TYPE: FLOAT_TYPE, STRING_TYPE: STRING_TYPE, BOOL_TYPE: BOOL_TYPE, NOTHING_TYPE: NOTHING_TYPE}
+	return &Environment{Vals: map[string]string{}, Funcs: map[string]Signature{}, Types: types, Newtypes: map[string]string{},
+		Structs: map[string][]ast.FormalArg{}, Enums: map[string][]ast.Variant{}, Interfaces: map[string][]ast.MethodSpec{},
+		Generics: map[string][]ast.TypeParam{}}
+}
+
+// new scope sharing functions and types with its parent
+func NewEnclosedEnvironment(outer *Environment) *Environment {
+	return &Environment{Vals: map[string]string{}, Funcs: outer.Funcs, Types: outer.Types, Newtypes: outer.Newtypes, Structs: outer.Structs, Enums: outer.Enums,
+		Interfaces: outer.Interfaces, Generics: outer.Generics, Outer: outer}
+}
+
+func openScope() {
+	env = NewEnclosedEnvironment(env)
+}
+
+func closeScope() {
+	env = env.Outer
+}
+
+func typeMethods(kind string) (Methods, bool) {
+	if base, ok := env.Newtypes[kind]; ok {
+		return newtypeMethods(kind, base), true
+	}
+
+	if methods, ok := TypeTable[kind]; ok {
+		return methods, true
+	}
+
+	return genericMethods(kind)
 }
 
 func MethodExist(kind, method string) bool {
-	methods, ok := TypeTable[kind]
+	methods, ok := typeMethods(kind)
 	if !ok {
 		return false
 	}
@@ -73,7 +189,7 @@ func MethodExist(kind, method string) bool {
 }
 
 func GetMethod(kind, method string) (Signature, bool) {
-	methods, ok := TypeTable[kind]
+	methods, ok := typeMethods(kind)
 	if !ok {
 		return Signature{}, false
 	}
@@ -86,6 +202,10 @@ func (e *Environment) Set(name, kind string) {
 	e.Vals[name] = kind
 }
 
+func SetMethodSignature(kind, name string, sig Signature) {
+	TypeTable[kind][name] = sig
+}
+
 func SetFunctionSignature(name string, sig Signature) {
 	env.Funcs[name] = sig
 }
@@ -95,19 +215,49 @@ func GetFunctionSignature(name string) (Signature, bool) {
 	return kind, ok
 }
 
+// variables found outside an anonymous function are captured by it
 func (e *Environment) Get(name string) (string, bool) {
 	kind, ok := e.Vals[name]
+	if !ok && e.Outer != nil {
+		kind, ok = e.Outer.Get(name)
+		if ok && e.Lambda != nil {
+			e.capture(name)
+		}
+	}
 	return kind, ok
 }
 
+func (e *Environment) capture(name string) {
+	for _, captured := range e.Lambda.Captures {
+		if captured == name {
+			return
+		}
+	}
+	e.Lambda.Captures = append(e.Lambda.Captures, name)
+}
+
+// whether a variable lives outside the nearest anonymous function
+func (e *Environment) Captured(name string) bool {
+	for scope := e; scope != nil; scope = scope.Outer {
+		if _, ok := scope.Vals[name]; ok {
+			return false
+		}
+
+		if scope.Lambda != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// only checks the current scope so inner blocks may shadow
 func (e *Environment) IdentExist(kind string) bool {
 	_, ok := e.Vals[kind]
 	return ok
 }
 
 func GetIdentType(name string) (string, bool) {
-	kind, ok := env.Vals[name]
-	return kind, ok
+	return env.Get(name)
 }
 
 func (e *Environment) TypeExist(kind string) bool {
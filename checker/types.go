@@ -0,0 +1,428 @@
+package checker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Lebonesco/go-compiler/ast"
+	"strings"
+)
+
+// composite types are kept as their source strings, e.g. List[Int]
+
+func ListOf(elem string) string {
+	return LIST_TYPE + "[" + elem + "]"
+}
+
+// element type of a List type
+func ListElem(kind string) (string, bool) {
+	args, ok := TypeArgs(kind, LIST_TYPE)
+	if !ok || len(args) != 1 {
+		return "", false
+	}
+	return args[0], true
+}
+
+func MapOf(key, value string) string {
+	return MAP_TYPE + "[" + key + ", " + value + "]"
+}
+
+// key and value types of a Map type
+func MapTypes(kind string) (string, string, bool) {
+	args, ok := TypeArgs(kind, MAP_TYPE)
+	if !ok || len(args) != 2 {
+		return "", "", false
+	}
+	return args[0], args[1], true
+}
+
+func TupleOf(elems []string) string {
+	return "(" + strings.Join(elems, ", ") + ")"
+}
+
+// element types of a tuple type
+func TupleTypes(kind string) ([]string, bool) {
+	if !strings.HasPrefix(kind, "(") || !strings.HasSuffix(kind, ")") {
+		return nil, false
+	}
+	return splitTypes(kind[1 : len(kind)-1]), true
+}
+
+func OptionalOf(elem string) string {
+	return elem + "?"
+}
+
+// type held by an optional type, func() Int? returns an optional
+func OptionalElem(kind string) (string, bool) {
+	if !strings.HasSuffix(kind, "?") || strings.HasPrefix(kind, "func(") {
+		return "", false
+	}
+	return kind[:len(kind)-1], true
+}
+
+func FuncOf(params []string, ret string) string {
+	return "func(" + strings.Join(params, ", ") + ") " + ret
+}
+
+// parameter and return types of a function type
+func FuncTypes(kind string) ([]string, string, bool) {
+	if !strings.HasPrefix(kind, "func(") {
+		return nil, "", false
+	}
+
+	depth := 0
+	for i, c := range kind {
+		switch c {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+			if depth != 0 {
+				continue
+			}
+
+			params := []string{}
+			if inner := kind[len("func("):i]; inner != "" {
+				params = splitTypes(inner)
+			}
+			return params, strings.TrimSpace(kind[i+1:]), true
+		}
+	}
+	return nil, "", false
+}
+
+// type arguments of Name[A, B]
+func TypeArgs(kind, name string) ([]string, bool) {
+	if !strings.HasPrefix(kind, name+"[") || !strings.HasSuffix(kind, "]") {
+		return nil, false
+	}
+	return splitTypes(kind[len(name)+1 : len(kind)-1]), true
+}
+
+// split a comma separated list of types, skipping nested commas
+func splitTypes(list string) []string {
+	var types []string
+	depth, start := 0, 0
+	for i, c := range list {
+		switch c {
+		case '[', '(':
+			depth++
+		case ']', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				types = append(types, strings.TrimSpace(list[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	return append(types, strings.TrimSpace(list[start:]))
+}
+
+// methods of generic types depend on their type arguments
+func genericMethods(kind string) (Methods, bool) {
+	if elem, ok := ListElem(kind); ok {
+		return Methods{
+			LEN:    {INT_TYPE, []string{}},
+			APPEND: {NOTHING_TYPE, []string{elem}},
+			INDEX:  {elem, []string{INT_TYPE}},
+			ITER:   {elem, []string{}},
+			PRINT:  {NOTHING_TYPE, []string{}}}, true
+	}
+
+	if key, value, ok := MapTypes(kind); ok {
+		return Methods{
+			LEN:    {INT_TYPE, []string{}},
+			HAS:    {BOOL_TYPE, []string{key}},
+			DELETE: {NOTHING_TYPE, []string{key}},
+			INDEX:  {value, []string{key}},
+			SET:    {NOTHING_TYPE, []string{key, value}},
+			ITER:   {key, []string{}},
+			PRINT:  {NOTHING_TYPE, []string{}}}, true
+	}
+
+	if elem, ok := OptionalElem(kind); ok {
+		methods := Methods{PRINT: {NOTHING_TYPE, []string{}}}
+		if MethodExist(elem, EQUAL) {
+			methods[EQUAL] = Signature{BOOL_TYPE, []string{kind}}
+			methods[NEQUAL] = Signature{BOOL_TYPE, []string{kind}}
+		}
+		return methods, true
+	}
+
+	// tuples compare element by element
+	if elems, ok := TupleTypes(kind); ok {
+		methods := Methods{PRINT: {NOTHING_TYPE, []string{}}}
+		for _, elem := range elems {
+			if !MethodExist(elem, EQUAL) {
+				return methods, true
+			}
+		}
+		methods[EQUAL] = Signature{BOOL_TYPE, []string{kind}}
+		methods[NEQUAL] = Signature{BOOL_TYPE, []string{kind}}
+		return methods, true
+	}
+	return nil, false
+}
+
+// a newtype has the methods of its base, with the base replaced by
+// the newtype, along with the methods declared on it
+func newtypeMethods(kind, base string) Methods {
+	swap := func(t string) string {
+		if t == base {
+			return kind
+		}
+		return t
+	}
+
+	methods := Methods{}
+	inherited, _ := typeMethods(base)
+	for name, sig := range inherited {
+		params := make([]string, len(sig.Params))
+		for i, param := range sig.Params {
+			params[i] = swap(param)
+		}
+		methods[name] = Signature{swap(sig.Return), params}
+	}
+
+	for name, sig := range TypeTable[kind] {
+		methods[name] = sig
+	}
+	return methods
+}
+
+// a written type with aliases replaced by the types they stand for
+func resolveType(kind string) (string, error) {
+	resolved := expandType(kind)
+	return resolved, checkType(resolved)
+}
+
+func expandType(kind string) string {
+	return mapType(kind, func(name string) string {
+		if resolved, ok := env.Types[name]; ok {
+			return resolved
+		}
+		return name // unknown, reported by checkType
+	})
+}
+
+// replace type parameters with the types bound to them
+func SubstituteType(kind string, bindings map[string]string) string {
+	return mapType(kind, func(name string) string {
+		if bound, ok := bindings[name]; ok && bound != "" {
+			return bound
+		}
+		return name
+	})
+}
+
+// rebuild a type with f applied to each type name in it
+func mapType(kind string, f func(string) string) string {
+	if params, ret, ok := FuncTypes(kind); ok {
+		return FuncOf(mapTypes(params, f), mapType(ret, f))
+	}
+
+	if elem, ok := OptionalElem(kind); ok {
+		return OptionalOf(mapType(elem, f))
+	}
+
+	if elems, ok := TupleTypes(kind); ok {
+		return TupleOf(mapTypes(elems, f))
+	}
+
+	if i := strings.Index(kind, "["); i != -1 {
+		args, _ := TypeArgs(kind, kind[:i])
+		return kind[:i] + "[" + strings.Join(mapTypes(args, f), ", ") + "]"
+	}
+	return f(kind)
+}
+
+func mapTypes(kinds []string, f func(string) string) []string {
+	mapped := make([]string, len(kinds))
+	for i, kind := range kinds {
+		mapped[i] = mapType(kind, f)
+	}
+	return mapped
+}
+
+// whether a type still mentions an unbound type parameter
+func hasUnbound(kind string, bindings map[string]string) bool {
+	unbound := false
+	mapType(kind, func(name string) string {
+		if bound, ok := bindings[name]; ok && bound == "" {
+			unbound = true
+		}
+		return name
+	})
+	return unbound
+}
+
+// bind the type parameters in a parameter type to the matching
+// parts of an argument type, false when the two cannot match
+func unify(param, arg string, bindings map[string]string) bool {
+	if bound, ok := bindings[param]; ok {
+		if bound == "" {
+			bindings[param] = arg
+			return true
+		}
+		return bound == arg
+	}
+
+	if params, ret, ok := FuncTypes(param); ok {
+		argParams, argRet, ok := FuncTypes(arg)
+		return ok && unifyAll(append(params, ret), append(argParams, argRet), bindings)
+	}
+
+	if elem, ok := OptionalElem(param); ok {
+		if argElem, ok := OptionalElem(arg); ok {
+			return unify(elem, argElem, bindings)
+		}
+		return unify(elem, arg, bindings) // a plain value is wrapped
+	}
+
+	if elems, ok := TupleTypes(param); ok {
+		argElems, ok := TupleTypes(arg)
+		return ok && unifyAll(elems, argElems, bindings)
+	}
+
+	if i := strings.Index(param, "["); i != -1 {
+		args, _ := TypeArgs(param, param[:i])
+		argArgs, ok := TypeArgs(arg, param[:i])
+		return ok && unifyAll(args, argArgs, bindings)
+	}
+	return param == arg
+}
+
+func unifyAll(params, args []string, bindings map[string]string) bool {
+	if len(params) != len(args) {
+		return false
+	}
+
+	for i := range params {
+		if !unify(params[i], args[i], bindings) {
+			return false
+		}
+	}
+	return true
+}
+
+// a constraint's methods as required of a type parameter, where the
+// interface names itself in a signature it means the parameter
+func constraintMethods(param, iface string) Methods {
+	self := map[string]string{iface: param}
+	methods := Methods{}
+	for name, sig := range TypeTable[iface] {
+		params := make([]string, len(sig.Params))
+		for i, kind := range sig.Params {
+			params[i] = SubstituteType(kind, self)
+		}
+		methods[name] = Signature{SubstituteType(sig.Return, self), params}
+	}
+	return methods
+}
+
+// a type argument meets a constraint when it has the constraint's methods
+func meetsConstraint(kind, iface string) bool {
+	for name, want := range constraintMethods(kind, iface) {
+		sig, ok := GetMethod(kind, name)
+		if !ok || !sameSignature(sig, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// make sure a written type is declared, Map keys need equality
+func checkType(kind string) error {
+	if params, ret, ok := FuncTypes(kind); ok {
+		for _, param := range params {
+			if err := checkType(param); err != nil {
+				return err
+			}
+		}
+		return checkType(ret)
+	}
+
+	if elem, ok := OptionalElem(kind); ok {
+		if _, nested := OptionalElem(elem); nested || elem == NOTHING_TYPE {
+			return errors.New(fmt.Sprintf("type %s cannot be optional", elem))
+		}
+		return checkType(elem)
+	}
+
+	if elem, ok := ListElem(kind); ok {
+		return checkType(elem)
+	}
+
+	if key, value, ok := MapTypes(kind); ok {
+		if err := checkType(key); err != nil {
+			return err
+		}
+
+		if !MethodExist(key, EQUAL) {
+			return errors.New(fmt.Sprintf("type %s cannot be a map key", key))
+		}
+		return checkType(value)
+	}
+
+	if elems, ok := TupleTypes(kind); ok {
+		for _, elem := range elems {
+			if err := checkType(elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !env.TypeExist(kind) {
+		return errors.New(fmt.Sprintf("unknown type %s", kind))
+	}
+	return nil
+}
+
+// variant of an enum type and its tag, the variant's position
+func LookupVariant(kind, name string) (ast.Variant, int, bool) {
+	for i, v := range env.Enums[kind] {
+		if v.Name == name {
+			return v, i, true
+		}
+	}
+	return ast.Variant{}, 0, false
+}
+
+// a type satisfies an interface when it has every method of the
+// interface with exactly the same signature
+func satisfies(kind, iface string) bool {
+	if _, ok := env.Interfaces[iface]; !ok {
+		return false
+	}
+
+	for name, want := range TypeTable[iface] {
+		sig, ok := GetMethod(kind, name)
+		if !ok || !sameSignature(sig, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameSignature(a, b Signature) bool {
+	return a.Return == b.Return && strings.Join(a.Params, ", ") == strings.Join(b.Params, ", ")
+}
+
+// structs, enums and newtypes
+func isUserType(kind string) bool {
+	_, isStruct := env.Structs[kind]
+	_, isEnum := env.Enums[kind]
+	_, isNewtype := env.Newtypes[kind]
+	return isStruct || isEnum || isNewtype
+}
+
+// type of a field of a struct type
+func FieldType(kind, field string) (string, bool) {
+	for _, f := range env.Structs[kind] {
+		if f.Arg == field {
+			return f.Type, true
+		}
+	}
+	return "", false
+}
@@ -22,7 +22,1167 @@ func TestOperations(t *testing.T) {
 		{`5 < 10;`, true},
 		{`true and true;`, true},
 		{`4 and 2;`, false},
-		{`true or false;`, true}}
+		{`true or false;`, true},
+		{`-5;`, true},
+		{`5 - -5 * 2;`, true},
+		{`not true;`, true},
+		{`not (5 > 3) and true;`, true},
+		{`-"5";`, false},
+		{`-true;`, false},
+		{`not 5;`, false},
+		{`10 / 3 % 2;`, true},
+		{`let b = 1 <= 2 and 3 >= 2 and 1 != 2 and 1 == 1;`, true},
+		{`"a" / "b";`, false},
+		{`"a" % 2;`, false},
+		{`true <= false;`, false}}
+
+	runTests(tests, t)
+}
+
+func TestComparisonType(t *testing.T) {
+	tests := []Test{
+		{`if 1 == 1 { PRINT(1); }`, true},
+		{`if 1 != 2 { PRINT(1); }`, true},
+		{`let x = 1 <= 2;
+		  x = false;`, true},
+		{`let x = 1 >= 2;
+		  x = 5;`, false},
+		{`let name = "Jeff";
+		  if name == "Jeff" { PRINT(name); }`, true},
+		{`"a" != "b";`, true},
+		{`"a" < "b" and "b" >= "a";`, true},
+		{`true == false;`, true},
+		{`true != (1 < 2);`, true},
+		{`true < false;`, false},
+		{`"a" == 1;`, false}}
+
+	runTests(tests, t)
+}
+
+func TestFloats(t *testing.T) {
+	tests := []Test{
+		{`let x = 1.5 + 2.25 * 3.0;`, true},
+		{`let x = 1e3 / 2.5E-2;`, true},
+		{`let x = -1.5;
+		  x = 2.0;`, true},
+		{`1.5 < 2.0 and 2.0 != 1.0;`, true},
+		{`1.5 + 2;`, false},
+		{`let x = 1.5;
+		  x = 2;`, false},
+		{`1.5 % 2.0;`, false},
+		{`let x = FLOAT(3) + 0.5;`, true},
+		{`let x = INT(3.7) + 1;`, true},
+		{`FLOAT(3.5);`, false},
+		{`INT(3);`, false},
+		{`for i in 0..INT(2.5) { PRINT(FLOAT(i)); }`, true}}
+
+	runTests(tests, t)
+}
+
+func TestLists(t *testing.T) {
+	tests := []Test{
+		{`let xs = [1, 2, 3];`, true},
+		{`let xs = [1, "2", 3];`, false},
+		{`let xs = [];`, false},
+		{`let xs List[Int] = [];`, true},
+		{`let xs List[String] = [1];`, false},
+		{`let xss = [[1], [2, 3], []];`, true},
+		{`let xs = [1, 2];
+		  let x = xs[0] + 1;`, true},
+		{`let xs = [1, 2];
+		  let x = xs["0"];`, false},
+		{`let x = 5;
+		  let y = x[0];`, false},
+		{`let xs = [1, 2];
+		  xs[0] = 5;`, true},
+		{`let xs = [1, 2];
+		  xs[0] = "5";`, false},
+		{`let xss = [[1, 2]];
+		  xss[0][1] = 5;
+		  xss[0] = [];`, true},
+		{`func f() List[Int] {
+			return [1];
+		  }
+		  f()[0] = 1;`, false},
+		{`let xs = [1, 2];
+		  APPEND(xs, 3);
+		  let n = LEN(xs) * 2;`, true},
+		{`let xs = [1, 2];
+		  APPEND(xs, "3");`, false},
+		{`LEN(5);`, false},
+		{`let xs = ["a", "b"];
+		  for x in xs {
+			PRINT(x + "!");
+		  }`, true},
+		{`for x in 5 {
+			PRINT(x);
+		  }`, false},
+		{`let xs = [1];
+		  for x in xs {
+			x = "a";
+		  }`, false},
+		{`func sum(xs List[Int]) Int {
+			let total = 0;
+			for x in xs {
+				total = total + x;
+			}
+			return total;
+		  }
+		  let s = sum([1, 2, 3]);
+		  let t = sum([]);`, true}}
+
+	runTests(tests, t)
+}
+
+func TestMaps(t *testing.T) {
+	tests := []Test{
+		{`let m = {"a": 1, "b": 2};`, true},
+		{`let m = {"a": 1, "b": "2"};`, false},
+		{`let m = {"a": 1, 2: 2};`, false},
+		{`let m = {};`, false},
+		{`let m Map[String, Int] = {};`, true},
+		{`let m Map[List[Int], Int] = {};`, false},
+		{`let m = {[1]: 1};`, false},
+		{`let m = {1: [1], 2: []};`, true},
+		{`let m = {"a": 1};
+		  let x = m["a"] + 1;`, true},
+		{`let m = {"a": 1};
+		  let x = m[1];`, false},
+		{`let m = {"a": 1};
+		  m["b"] = 2;
+		  m["a"] = m["a"] + 1;`, true},
+		{`let m = {"a": 1};
+		  m["b"] = "2";`, false},
+		{`let m = {"a": [1]};
+		  m["a"][0] = 2;`, true},
+		{`let m = {"a": 1};
+		  let ok = HAS(m, "a") and LEN(m) == 1;
+		  DELETE(m, "a");`, true},
+		{`let m = {"a": 1};
+		  HAS(m, 1);`, false},
+		{`let m = {1: true};
+		  for k in m {
+			PRINT(k + 1);
+		  }`, true},
+		{`func count(m Map[String, Int]) Int {
+			return LEN(m);
+		  }
+		  let n = count({});`, true},
+		{`func f(m Map[Nothing, Int]) Int {
+			return 1;
+		  }`, false}}
+
+	runTests(tests, t)
+}
+
+func TestStructs(t *testing.T) {
+	tests := []Test{
+		{`type Point struct { x Int, y Int }
+		  let p = Point(1, 2);
+		  let x = p.x + p.y;`, true},
+		{`type Point struct { x Int, y Int }
+		  let p = Point(1);`, false},
+		{`type Point struct { x Int, y Int }
+		  let p = Point(1, "2");`, false},
+		{`type Point struct { x Int, y Int }
+		  let p = Point(1, 2);
+		  let z = p.z;`, false},
+		{`let x = 5;
+		  let y = x.y;`, false},
+		{`type Point struct { x Int, y Int }
+		  let p = Point(1, 2);
+		  p.x = 3;
+		  p.y = p.x * 2;`, true},
+		{`type Point struct { x Int, y Int }
+		  let p = Point(1, 2);
+		  p.x = "3";`, false},
+		{`type Point struct { x Int, y Int }
+		  func origin() Point {
+			return Point(0, 0);
+		  }
+		  origin().x = 1;`, false},
+		{`type Point struct { x Int, y Int }
+		  type Line struct { a Point, b Point, tags List[String] }
+		  let l = Line(Point(0, 0), Point(1, 1), []);
+		  l.b.x = 2;
+		  l.tags[0] = "a";
+		  let ps = [l.a, l.b];
+		  ps[0].y = l.b.x;`, true},
+		{`type Point struct { x Int, y Int }
+		  func move(p Point, dx Int) Point {
+			p.x = p.x + dx;
+			return p;
+		  }
+		  let p = move(Point(1, 2), 3);
+		  PRINT(p);`, true},
+		{`type Point struct { x Int, x Int }`, false},
+		{`type Point struct { x Int, PRINT Int }`, false},
+		{`type Point struct { x Int }
+		  type Point struct { y Int }`, false},
+		{`type Int struct { x Int }`, false},
+		{`type Tuple struct { x Int }`, false},
+		{`type Optional enum { Some(Int), Empty }`, false},
+		{`type Base Int;`, false},
+		{`type List = Int;`, false},
+		{`func Tuple() Int {
+			return 1;
+		  }`, false},
+		{`type Node struct { next Node }`, false},
+		{`type Point struct { x Number }`, false},
+		{`type Point struct { x Int }
+		  func Point() Int {
+			return 1;
+		  }`, false},
+		{`func f(x Number) Int {
+			return 1;
+		  }`, false},
+		{`func f() Number {
+			return 1;
+		  }`, false},
+		{`let x Number = 1;`, false},
+		{`type Point struct { x Int }
+		  let m = {Point(1): 1};`, false},
+		{`type Empty struct {}
+		  let e = Empty();`, true}}
+
+	runTests(tests, t)
+}
+
+func TestMethods(t *testing.T) {
+	tests := []Test{
+		{`type Point struct { x Int, y Int }
+		  func (p Point) norm() Int {
+			return p.x * p.x + p.y * p.y;
+		  }
+		  let n = Point(1, 2).norm() + 1;`, true},
+		{`type Point struct { x Int, y Int }
+		  func (p Point) scale(k Int) Point {
+			return Point(p.x * k, p.y * k);
+		  }
+		  let p = Point(1, 2).scale(2).scale(3);
+		  let x = p.scale(1).x;`, true},
+		{`type Point struct { x Int, y Int }
+		  func (p Point) scale(k Int) Point {
+			return Point(p.x * k, p.y * k);
+		  }
+		  let p = Point(1, 2).scale("2");`, false},
+		{`type Point struct { x Int, y Int }
+		  func (p Point) scale(k Int) Point {
+			return Point(p.x * k, p.y * k);
+		  }
+		  let p = Point(1, 2).scale();`, false},
+		{`type Point struct { x Int, y Int }
+		  let n = Point(1, 2).norm();`, false},
+		{`type Point struct { x Int, y Int }
+		  func (p Point) norm() Int {
+			return "big";
+		  }`, false},
+		{`type Point struct { x Int, y Int }
+		  func (p Point) norm() Int {
+			return 1;
+		  }
+		  func (p Point) norm() Int {
+			return 2;
+		  }`, false},
+		{`type Point struct { x Int, y Int }
+		  func (p Point) x() Int {
+			return 1;
+		  }`, false},
+		{`type Point struct { x Int, y Int }
+		  func (p Point) PRINT() Int {
+			return 1;
+		  }`, false},
+		{`func (x Int) double() Int {
+			return x * 2;
+		  }`, false},
+		{`type Point struct { x Int, y Int }
+		  func norm(p Point) Int {
+			return p.x;
+		  }
+		  func (p Point) norm() Int {
+			return norm(p);
+		  }
+		  let n = norm(Point(1, 2)) + Point(1, 2).norm();`, true},
+		{`let xs = [1, 2];
+		  xs.APPEND(3);
+		  let n = xs.LEN(1);`, false},
+		{`let xs = [1, 2];
+		  xs.APPEND(3);
+		  let n = xs.LEN();
+		  n.PRINT();`, true},
+		{`let xs = [1, 2];
+		  let x Int = xs.ITER();`, false},
+		{`let xs = [1, 2];
+		  let x = xs.INDEX(0);`, false},
+		{`let m = {"a": 1};
+		  m.SET("b", 2);`, false},
+		{`type P struct { n Int }
+		  func (p P) INDEX(i Int) Int {
+			return p.n + i;
+		  }
+		  let a = P(1).INDEX(2);`, false}}
+
+	runTests(tests, t)
+}
+
+func TestOperatorMethods(t *testing.T) {
+	vec := `type Vec struct { x Int, y Int }
+		  func (a Vec) PLUS(b Vec) Vec {
+			return Vec(a.x + b.x, a.y + b.y);
+		  }
+		  func (a Vec) TIMES(k Int) Vec {
+			return Vec(a.x * k, a.y * k);
+		  }
+		  func (a Vec) EQ(b Vec) Bool {
+			return a.x == b.x and a.y == b.y;
+		  }
+		  func (a Vec) NEG() Vec {
+			return Vec(-a.x, -a.y);
+		  }
+		  `
+	tests := []Test{
+		{vec + `let v = Vec(1, 2) + Vec(3, 4);`, true},
+		{vec + `let v = -(Vec(1, 2) * 3) + Vec(0, 1);`, true},
+		{vec + `let same = Vec(1, 2) == Vec(1, 2) or Vec(1, 2) == -Vec(1, 2);`, true},
+		{vec + `let v = Vec(1, 2) * Vec(3, 4);`, false},
+		{vec + `let v = Vec(1, 2) + 1;`, false},
+		{vec + `let v = Vec(1, 2) - Vec(3, 4);`, false},
+		{vec + `let v = Vec(1, 2) != Vec(3, 4);`, false},
+		{vec + `let b Bool = Vec(1, 2) + Vec(3, 4);`, false},
+		{vec + `let m = {Vec(1, 2): "a"};
+		  let s = m[Vec(1, 2)];`, true},
+		{`type Vec struct { x Int }
+		  let m = {Vec(1): "a"};`, false},
+		{`type Vec struct { x Int }
+		  func (a Vec) PLUS(b Vec, c Vec) Vec {
+			return a;
+		  }`, false},
+		{`type Vec struct { x Int }
+		  func (a Vec) NEG(b Vec) Vec {
+			return a;
+		  }`, false},
+		{`type Vec struct { x Int }
+		  func (a Vec) LT(b Vec) Int {
+			return 1;
+		  }`, false},
+		{`type Vec struct { x Int }
+		  func (a Vec) EQ(b Int) Bool {
+			return a.x == b;
+		  }`, false},
+		{`type P struct { n Int }
+		  func (p P) INDEX(i Int) Int {
+			return p.n + i;
+		  }
+		  let a Int = P(1)[2];`, true},
+		{`type P struct { n Int }
+		  func (p P) INDEX() Int {
+			return p.n;
+		  }
+		  let a = P(1)[0];`, false},
+		{`type P struct { n Int }
+		  func (p P) INDEX(i Int) Nothing {
+			return PRINT(i);
+		  }`, false},
+		{`type P struct { n Int }
+		  func (p P) INDEX(i Int) Int {
+			return p.n + i;
+		  }
+		  let p = P(1);
+		  p[0] = 5;`, false},
+		{`type P struct { n Int }
+		  func (p P) ITER() Int {
+			return p.n;
+		  }`, false},
+		{`type P struct { n Int }
+		  func (p P) SET(i Int, v Int) Int {
+			return v;
+		  }`, false},
+		{`type Row List[Int];
+		  let r = Row([1, 2]);
+		  r[0] = 5;`, true}}
+
+	runTests(tests, t)
+}
+
+func TestEnums(t *testing.T) {
+	shape := `type Shape enum { Circle(Float), Rect(Float, Float), Empty }
+		  `
+	tests := []Test{
+		{shape + `let s = Shape::Circle(1.0);
+		  let e = Shape::Empty;`, true},
+		{shape + `let s = Shape::Circle(1);`, false},
+		{shape + `let s = Shape::Rect(1.0);`, false},
+		{shape + `let s = Shape::Square(1.0);`, false},
+		{shape + `let s = Color::Red;`, false},
+		{shape + `let s Shape = Shape::Empty;
+		  match s {
+			Shape::Circle(r) { PRINT(r * r); }
+			Shape::Rect(w, h) { PRINT(w * h); }
+			Shape::Empty { PRINT("empty"); }
+		  }`, true},
+		{shape + `match Shape::Empty {
+			Shape::Circle(r) { PRINT(r); }
+			Shape::Empty { PRINT("empty"); }
+		  }`, false},
+		{shape + `match Shape::Empty {
+			Shape::Circle(r) { PRINT(r); }
+			_ { PRINT("other"); }
+		  }`, true},
+		{shape + `match Shape::Empty {
+			_ { PRINT("other"); }
+			Shape::Circle(r) { PRINT(r); }
+		  }`, false},
+		{shape + `match Shape::Empty {
+			Shape::Circle(r) { PRINT(r); }
+			Shape::Circle(r) { PRINT(r); }
+			_ { PRINT("other"); }
+		  }`, false},
+		{shape + `match Shape::Empty {
+			Shape::Rect(w) { PRINT(w); }
+			_ { PRINT("other"); }
+		  }`, false},
+		{shape + `match Shape::Empty {
+			Shape::Rect(w, w) { PRINT(w); }
+			_ { PRINT("other"); }
+		  }`, false},
+		{shape + `match Shape::Empty {
+			Shape::Rect(_, h) { PRINT(h); }
+			_ { PRINT("other"); }
+		  }`, true},
+		{shape + `match Shape::Empty {
+			Shape::Circle(r) { PRINT(r); }
+			_ { PRINT(r); }
+		  }`, false},
+		{shape + `match 5 {
+			_ { PRINT(1); }
+		  }`, false},
+		{shape + `type Color enum { Red, Green }
+		  match Shape::Empty {
+			Color::Red { PRINT(1); }
+			_ { PRINT(2); }
+		  }`, false},
+		{shape + `func area(s Shape) Float {
+			match s {
+				Shape::Circle(r) { return 3.0 * r * r; }
+				Shape::Rect(w, h) { return w * h; }
+				Shape::Empty { return 0.0; }
+			}
+		  }
+		  let a = area(Shape::Rect(1.0, 2.0)) + 1.0;`, true},
+		{shape + `func area(s Shape) Float {
+			match s {
+				Shape::Circle(r) { return 3.0 * r * r; }
+				_ { PRINT("no area"); }
+			}
+		  }`, false},
+		{shape + `let area = match Shape::Circle(2.0) {
+			Shape::Circle(r) => r * r,
+			Shape::Rect(w, h) => w * h,
+			Shape::Empty => 0.0,
+		  };
+		  let bigger = area + 1.0;`, true},
+		{shape + `let area = match Shape::Empty {
+			Shape::Circle(r) => r,
+			_ => 0,
+		  };`, false},
+		{shape + `let area = match Shape::Empty {
+			Shape::Circle(r) => r,
+		  };`, false},
+		{`type Color enum { Red, Green }
+		  let same = Color::Red == Color::Green;
+		  let names = {Color::Red: "red"};`, true},
+		{`type Color enum { Red, Green }
+		  type Paint enum { Custom(List[Int]), Named(Color) }
+		  let same = Paint::Named(Color::Red) == Paint::Named(Color::Red);`, false},
+		{`type Color enum { Red, Red }`, false},
+		{`type Color enum { Red, PRINT }`, false},
+		{`type Node enum { Leaf, Branch(Node) }`, false},
+		{`type Color enum { Red, Green }
+		  func (c Color) name() String {
+			match c {
+				Color::Red { return "red"; }
+				Color::Green { return "green"; }
+			}
+		  }
+		  let n = Color::Red.name();`, true},
+		{`type S enum { tag, B }`, false},
+		{`type S enum { A(Int), A_0 }`, false},
+		{`type S enum { A(Int), B }
+		  func (s S) A_0() Int {
+			return 1;
+		  }`, false},
+		{`type S enum { A(Int), B }
+		  func (s S) tag() Int {
+			return 1;
+		  }`, false},
+		{`type S enum { A_1(Int), A }`, true}}
+
+	runTests(tests, t)
+}
+
+func TestTuples(t *testing.T) {
+	divmod := `func divmod(a Int, b Int) (Int, Int) {
+			return (a / b, a % b);
+		  }
+		  `
+	tests := []Test{
+		{divmod + `let (q, r) = divmod(7, 2);
+		  let x = q + r;`, true},
+		{divmod + `let (q, r, s) = divmod(7, 2);`, false},
+		{divmod + `let (q) = divmod(7, 2);`, false},
+		{divmod + `let (q, _) = divmod(7, 2);
+		  let x = q + 1;`, true},
+		{divmod + `let (q, q) = divmod(7, 2);`, false},
+		{divmod + `let q = 1;
+		  let (q, r) = divmod(7, 2);`, false},
+		{divmod + `let (q, r) = 5;`, false},
+		{divmod + `let (q, r) = divmod(7, 2);
+		  let s String = r;`, false},
+		{`func f() (Int, String) {
+			return (1, 2);
+		  }`, false},
+		{`func f() (Int, String) {
+			return 1;
+		  }`, false},
+		{`func f() (Int, List[String]) {
+			return (1, []);
+		  }
+		  let (n, xs) = f();
+		  APPEND(xs, "a");`, true},
+		{`let pair = (1, "a");
+		  let (n, s) = pair;
+		  let p (Int, String) = (n + 1, s + "b");`, true},
+		{`let p (Int, String) = ("a", 1);`, false},
+		{`let ps = [(1, "a"), (2, "b")];
+		  for p in ps {
+			let (n, s) = p;
+			PRINT(n);
+		  }
+		  let same = ps[0] == (1, "a");`, true},
+		{`let ps = [(1, "a"), ("b", 2)];`, false},
+		{`let grid = {(0, 0): "origin"};
+		  grid[(1, 2)] = "x";`, true},
+		{`let m = {(0, [1]): "a"};`, false},
+		{`let x (Int, Number) = (1, 2);`, false},
+		{`let ((a, b), c) = ((1, 2), 3);`, false},
+		{`let (ab, c) = ((1, 2), 3);
+		  let (a, b) = ab;
+		  let n = a + b + c;`, true}}
+
+	runTests(tests, t)
+}
+
+func TestOptionals(t *testing.T) {
+	find := `func find(xs List[Int], x Int) Int? {
+			for i in 0..LEN(xs) {
+				if xs[i] == x {
+					return i;
+				}
+			}
+			return none;
+		  }
+		  `
+	tests := []Test{
+		{`let x Int? = 5;
+		  let y Int? = none;
+		  x = none;
+		  y = 6;`, true},
+		{`let x = none;`, false},
+		{`let x Int = none;`, false},
+		{`let x Int? = "5";`, false},
+		{`let x Int? = 5;
+		  let y = x + 1;`, false},
+		{`let x Int? = 5;
+		  let y Int = x;`, false},
+		{`let x Int? = 5;
+		  if let v = x {
+			let y = v + 1;
+		  }`, true},
+		{`let x Int? = 5;
+		  if let v = x {
+			PRINT(v);
+		  }
+		  let y = v;`, false},
+		{`let x = 5;
+		  if let v = x {
+			PRINT(v);
+		  }`, false},
+		{`let x Int? = 5;
+		  if let x = x {
+			let y = x + 1;
+		  } else {
+			PRINT("none");
+		  }`, true},
+		{`let a Int? = none;
+		  let b String? = "b";
+		  if let v = a {
+			PRINT(v);
+		  } else if let s = b {
+			PRINT(s + "!");
+		  } else if a == none {
+			PRINT("neither");
+		  }`, true},
+		{find + `let i = find([1, 2], 2);
+		  let found = i != none and i == 1;`, true},
+		{find + `func index(xs List[Int], x Int) Int {
+			if let i = find(xs, x) {
+				return i;
+			} else {
+				return -1;
+			}
+		  }`, true},
+		{`func f(x Int?) Int? {
+			return x;
+		  }
+		  let a = f(1);
+		  let b = f(none);`, true},
+		{`let xs List[Int?] = [1, none, 3];
+		  let m Map[String, Int?] = {"a": none, "b": 1};
+		  let p (Int?, String) = (none, "x");`, true},
+		{`let xs = [1, none];`, false},
+		{`let x Int?? = none;`, false},
+		{`let x Nothing? = none;`, false},
+		{`let x List[Int]? = [];
+		  if let xs = x {
+			APPEND(xs, 1);
+		  }`, true},
+		{`let x List[Int]? = [];
+		  let same = x == none;`, false}}
+
+	runTests(tests, t)
+}
+
+func TestInterfaces(t *testing.T) {
+	shapes := `type Circle struct { r Float }
+		  type Square struct { side Float }
+		  type Shape interface {
+			area() Float,
+			bigger(other Shape) Bool,
+		  }
+		  func (c Circle) area() Float {
+			return 3.0 * c.r * c.r;
+		  }
+		  func (c Circle) bigger(other Shape) Bool {
+			return c.area() > other.area();
+		  }
+		  func (s Square) area() Float {
+			return s.side * s.side;
+		  }
+		  `
+	tests := []Test{
+		{shapes + `func (s Square) bigger(other Shape) Bool {
+			return s.area() > other.area();
+		  }
+		  let s Shape = Circle(1.0);
+		  let shapes List[Shape] = [s, Square(2.0)];
+		  let total = 0.0;
+		  for x in shapes {
+			if x.bigger(s) {
+				total = total + x.area();
+			}
+		  }
+		  PRINT(s);`, true},
+		{shapes + `let s Shape = Square(2.0);`, false},
+		{shapes + `func (s Square) bigger(other Circle) Bool {
+			return true;
+		  }
+		  let s Shape = Square(2.0);`, false},
+		{shapes + `let s Shape = Circle(1.0);
+		  let r = s.r;`, false},
+		{shapes + `let s Shape = Circle(1.0);
+		  let c Circle = s;`, false},
+		{shapes + `let s Shape? = Circle(1.0);
+		  s = none;`, true},
+		{`type Printable interface {}
+		  func display(x Printable) Nothing {
+			return PRINT(x);
+		  }
+		  let a = display(1);
+		  let b = display([1, 2]);`, true},
+		{`type Sized interface { LEN() Int }
+		  let a Sized = [1, 2];
+		  let b Sized = {"a": 1};
+		  let n = a.LEN() + b.LEN();`, true},
+		{`type Sized interface { LEN() Int }
+		  let a Sized = "abc";`, false},
+		{`type Shape interface { area() Float, area() Float }`, false},
+		{`type Shape interface { area() Area }`, false},
+		{`type Shape interface { area() Float }
+		  func (s Shape) perimeter() Float {
+			return 0.0;
+		  }`, false},
+		{`type Foo interface { PLUS() Int }
+		  func f(a Foo, b Foo) Int {
+			return a + b;
+		  }`, false},
+		{`type Foo interface { INDEX() Int }
+		  func f(a Foo) Int {
+			return a[1];
+		  }`, false},
+		{`type Foo interface { PLUS() Int }
+		  func f[T Foo](a T, b T) Int {
+			return a + b;
+		  }`, false},
+		{`type Foo interface { LT(other Int) Bool }`, false},
+		{`type Foo interface { ITER() Int }`, false},
+		{`type Addable interface { PLUS(other Addable) Addable, INDEX(i Int) Int }
+		  func f(a Addable, b Addable) Int {
+			let c = a + b;
+			return c[0];
+		  }`, true},
+	}
+
+	runTests(tests, t)
+}
+
+func TestGenerics(t *testing.T) {
+	ordered := `type Ordered interface { GT(other Ordered) Bool }
+		  func max[T Ordered](a T, b T) T {
+			if a > b {
+				return a;
+			}
+			return b;
+		  }
+		  `
+	tests := []Test{
+		{ordered + `let a Int = max(1, 2);
+		  let b Float = max(1.5, 0.5);
+		  let c String = max("a", "b");`, true},
+		{ordered + `let a = max(1, 2.5);`, false},
+		{ordered + `let a = max(true, false);`, false},
+		{ordered + `let a = max([1], [2]);`, false},
+		{`func swap[A, B](p (A, B)) (B, A) {
+			let (a, b) = p;
+			return (b, a);
+		  }
+		  let (s, i) = swap((1, "one"));
+		  let n Int = i + 1;
+		  let m String = s + "!";`, true},
+		{`type Equal interface { EQ(other Equal) Bool }
+		  func contains[T Equal](xs List[T], x T) Bool {
+			for y in xs {
+				if y == x {
+					return true;
+				}
+			}
+			return false;
+		  }
+		  let a = contains([1, 2], 2);
+		  let b = contains(["a"], "b");
+		  let c = contains([1, 2], "b");`, false},
+		{`func first[T](xs List[T]) T? {
+			if LEN(xs) == 0 {
+				return none;
+			}
+			return xs[0];
+		  }
+		  let a Int? = first([1, 2]);
+		  let b String? = first(["a"]);`, true},
+		{`func first[T](xs List[T]) T {
+			return xs[0];
+		  }
+		  let a = first([]);`, false},
+		{`func show[T](x T) Nothing {
+			return PRINT(x);
+		  }`, false},
+		{`func same[T](x T) T {
+			return x + x;
+		  }`, false},
+		{`func make[T]() List[T] {
+			return [];
+		  }`, false},
+		{`func f[Int](x Int) Int {
+			return x;
+		  }`, false},
+		{`func f[T, T](x T) T {
+			return x;
+		  }`, false},
+		{`func f[T Int](x T) T {
+			return x;
+		  }`, false},
+		{`func f[T](x T) T {
+			return x;
+		  }
+		  let a T = 5;`, false},
+		{ordered + `func max3[T Ordered](a T, b T, c T) T {
+			return max(max(a, b), c);
+		  }
+		  let m = max3(1, 5, 3) + 1;`, true},
+		{ordered + `func pick[T](a T, b T) T {
+			return max(a, b);
+		  }`, false},
+		{`func deep[T](x T, n Int) Int {
+			if n == 0 {
+				return 0;
+			}
+			return deep([x], n - 1);
+		  }`, false},
+		{`func ping[T](x T, n Int) Int {
+			if n == 0 {
+				return 0;
+			}
+			return pong((x, 1), n - 1);
+		  }
+		  func pong[U](y U, n Int) Int {
+			return ping(y, n);
+		  }`, false},
+		{`func count[T](xs List[T], n Int) Int {
+			if n == 0 {
+				return LEN(xs);
+			}
+			return count(xs, n - 1);
+		  }
+		  func wrap[T](x T) Int {
+			return count([x], 2);
+		  }
+		  let a = wrap(1);`, true},
+	}
+
+	runTests(tests, t)
+}
+
+func TestFunctionValues(t *testing.T) {
+	funcs := `func inc(x Int) Int {
+			return x + 1;
+		  }
+		  func apply(f func(Int) Int, x Int) Int {
+			return f(x);
+		  }
+		  func adder() func(Int) Int {
+			return inc;
+		  }
+		  `
+	tests := []Test{
+		{funcs + `let f = inc;
+		  let a Int = f(1) + apply(inc, 2) + apply(f, 3);
+		  let b = adder()(4);`, true},
+		{funcs + `let f func(Int) Int = inc;
+		  let fs List[func(Int) Int] = [inc, f];
+		  let c = fs[1](5);`, true},
+		{funcs + `let f func(Int) Bool = inc;`, false},
+		{funcs + `let a = apply(inc, "2");`, false},
+		{funcs + `let f = inc;
+		  let a = f(1, 2);`, false},
+		{funcs + `let x = 5;
+		  let a = x(1);`, false},
+		{funcs + `let a = inc(1)(2);`, false},
+		{funcs + `let f = inc;
+		  PRINT(f);`, false},
+		{funcs + `let f = inc;
+		  let same = f == inc;`, false},
+		{funcs + `func inc2(inc Int) Int {
+			return inc(1);
+		  }`, false},
+		{`type Button struct { label String, onClick func(String) String }
+		  func shout(s String) String {
+			return s + "!";
+		  }
+		  let b = Button("ok", shout);
+		  let s String = b.onClick(b.label);`, true},
+		{`type Point struct { x Int, y Int }
+		  let mk = Point;`, false},
+		{`func id[T](x T) T {
+			return x;
+		  }
+		  let f = id;`, false},
+		{`func none2() Nothing {
+			return PRINT("hi");
+		  }
+		  let f func() Nothing = none2;
+		  f();`, true},
+		{`func find(x Int) Int? {
+			return none;
+		  }
+		  let f func(Int) Int? = find;
+		  if let v = f(1) {
+			PRINT(v);
+		  }`, true},
+		{`func twice[T](f func(T) T, x T) T {
+			return f(f(x));
+		  }
+		  func inc(x Int) Int {
+			return x + 1;
+		  }
+		  let a Int = twice(inc, 1);`, true},
+	}
+
+	runTests(tests, t)
+}
+
+func TestClosures(t *testing.T) {
+	tests := []Test{
+		{`let add1 = func(x Int) Int { return x + 1; };
+		  let a Int = add1(2);`, true},
+		{`func adder(n Int) func(Int) Int {
+			return func(x Int) Int { return x + n; };
+		  }
+		  let add2 = adder(2);
+		  let a = add2(1) + adder(3)(4);`, true},
+		{`let n = 1;
+		  let f = func(x Int, y Int) Int {
+			let z = x + y;
+			return z * n;
+		  };
+		  let g = func() Int { return f(1, 2); };`, true},
+		{`let f = func(x Int) Int { return y; };`, false},
+		{`let f = func(x Int) Int { return x; };
+		  let y = x;`, false},
+		{`let f = func(x Int) Int { return "x"; };`, false},
+		{`let f = func(x Int) Int { return x; };
+		  let a = f("1");`, false},
+		{`let n = 0;
+		  let inc = func() Int {
+			n = n + 1;
+			return n;
+		  };`, false},
+		{`let xs = [1];
+		  let f = func() Int {
+			xs[0] = 2;
+			return 0;
+		  };`, false},
+		{`let xs = [1];
+		  let f = func() Int {
+			xs.APPEND(2);
+			return 0;
+		  };`, false},
+		{`let f = func() Int {
+			let xs = [1];
+			xs.APPEND(2);
+			xs[0] = 3;
+			return LEN(xs);
+		  };`, true},
+		{`for i in 0..3 {
+			let f = func() Int {
+				break;
+				return i;
+			};
+		  }`, false},
+		{`func f() Int {
+			let g = func() String { return "a"; };
+			return 1;
+		  }`, true},
+		{`let n = 2;
+		  let outer = func(x Int) func(Int) Int {
+			return func(y Int) Int { return x + y + n; };
+		  };
+		  let a Int = outer(1)(2);`, true},
+		{`let a = func(x Int) Int { return x * 2; }(4) + 1;`, true},
+		{`let f func(Int) Bool = func(x Int) Int { return x; };`, false},
+		{`type Point struct { x Int, y Int }
+		  func (p Point) shifter() func(Int) Point {
+			return func(d Int) Point { return Point(p.x + d, p.y + d); };
+		  }
+		  let q = Point(1, 2).shifter()(3);`, true},
+	}
+
+	runTests(tests, t)
+}
+
+func TestProcedures(t *testing.T) {
+	tests := []Test{
+		{`func greet(name String) {
+			PRINT("hi " + name);
+		  }
+		  greet("bob");`, true},
+		{`func greet(name String) {
+			PRINT("hi " + name);
+		  }
+		  let x Nothing = greet("bob");`, true},
+		{`func greet(name String) {
+			PRINT("hi " + name);
+		  }
+		  let x Int = greet("bob");`, false},
+		{`func check(n Int) {
+			if n < 0 {
+				return;
+			}
+			PRINT(n);
+		  }`, true},
+		{`func check(n Int) {
+			return n;
+		  }`, false},
+		{`func f(n Int) Int {
+			return;
+		  }`, false},
+		{`return;`, false},
+		{`func log(xs List[Int]) {
+			for x in xs {
+				if x == 0 {
+					return;
+				}
+				PRINT(x);
+			}
+		  }`, true},
+		{`type Counter struct { n Int }
+		  func (c Counter) report() {
+			PRINT(c.n);
+		  }
+		  Counter(1).report();`, true},
+		{`func each[T](xs List[T], f func(T) Nothing) {
+			for x in xs {
+				f(x);
+			}
+		  }
+		  each([1, 2], func(x Int) { PRINT(x); });`, true},
+		{`let f func(Int) Nothing = func(x Int) {
+			if x > 1 {
+				return;
+			}
+		  };`, true},
+		{`let f func(Int) Int = func(x Int) { PRINT(x); };`, false},
+		{`let f = func() { return 1; };`, false},
+	}
+
+	runTests(tests, t)
+}
+
+func TestRecursion(t *testing.T) {
+	tests := []Test{
+		{`func fact(n Int) Int {
+			if n <= 1 {
+				return 1;
+			}
+			return n * fact(n - 1);
+		  }`, true},
+		{`func isEven(n Int) Bool {
+			if n == 0 {
+				return true;
+			}
+			return isOdd(n - 1);
+		  }
+		  func isOdd(n Int) Bool {
+			if n == 0 {
+				return false;
+			}
+			return isEven(n - 1);
+		  }
+		  let a = isEven(4);`, true},
+		{`func f() Int {
+			return g("a");
+		  }
+		  func g(x Int) Int {
+			return x;
+		  }`, false},
+		{`func f() String {
+			return g(1);
+		  }
+		  func g(x Int) Int {
+			return x;
+		  }`, false},
+		{`func f() Int {
+			return 1;
+		  }
+		  func f() Int {
+			return 2;
+		  }`, false},
+		{`func PRINT() Int {
+			return 1;
+		  }`, false},
+		{`func show(x Int) Int {
+			return x;
+		  }
+		  PRINT([1]);`, false},
+		{`func main() {
+			PRINT(1);
+		  }`, false},
+		{`type Span struct { lo Int, hi Int }
+		  func (s Span) sum() Int {
+			if s.lo > s.hi {
+				return 0;
+			}
+			return s.lo + Span(s.lo + 1, s.hi).sum();
+		  }`, true},
+		{`type Shape interface { area() Int }
+		  type Square struct { side Int }
+		  func total(s Shape) Int {
+			return s.area();
+		  }
+		  func (sq Square) area() Int {
+			return sq.side * sq.side;
+		  }
+		  func (sq Square) twice() Int {
+			return total(sq) * 2;
+		  }`, true},
+		{`func firsts[T](xs List[T], n Int) List[T] {
+			if n == 0 {
+				let empty List[T] = [];
+				return empty;
+			}
+			let rest = firsts(xs, n - 1);
+			rest.APPEND(xs[n - 1]);
+			return rest;
+		  }
+		  let a List[String] = firsts(["a"], 1);`, true},
+		{`func f() Int {
+			return g();
+		  }`, false},
+	}
+
+	runTests(tests, t)
+}
+
+func TestTypes(t *testing.T) {
+	tests := []Test{
+		{`type UserId = Int;
+		  let id UserId = 5;
+		  let x Int = id + 1;`, true},
+		{`type UserId = Int;
+		  type Ids = List[UserId];
+		  let ids Ids = [1, 2];
+		  let xs List[Int] = ids;`, true},
+		{`type Meters Int;
+		  let a = Meters(5);
+		  let b = a + Meters(2);
+		  let c Int = Int(b);`, true},
+		{`type Meters Int;
+		  let a = Meters(5);
+		  let b = a + 2;`, false},
+		{`type Meters Int;
+		  let a Meters = 5;`, false},
+		{`type Meters Int;
+		  let a = Meters(5);
+		  let b Int = a;`, false},
+		{`type Meters Int;
+		  type Feet Int;
+		  let a = Feet(Meters(5));`, false},
+		{`type Meters Int;
+		  let a = Meters("5");`, false},
+		{`type Meters Int;
+		  let a = Meters(5) < Meters(6);`, true},
+		{`type Ids List[Int];
+		  let ids = Ids([1, 2]);
+		  ids.APPEND(3);
+		  for id in ids {
+			PRINT(id + LEN(ids));
+		  }`, true},
+		{`type Meters Int;
+		  func (m Meters) feet() Float {
+			return FLOAT(Int(m)) * 3.28;
+		  }
+		  let f = Meters(2).feet();`, true},
+		{`type UserId = Int;
+		  func (id UserId) next() Int {
+			return id + 1;
+		  }`, false},
+		{`type Point struct {
+			x Int,
+			y Int
+		  }
+		  type P = Point;
+		  let p P = P(1, 2);
+		  let q Point = p;`, true},
+		{`type Meters Int;
+		  let m Map[Meters, String] = {Meters(1): "one"};`, true},
+		{`type Int = String;`, false},
+		{`type A = B;`, false},
+		{`type Empty Nothing;`, false},
+		{`type Meters Int;
+		  type Meters Float;`, false},
+		{`type UserId = Int;
+		  func UserId(x Int) Int {
+			return x;
+		  }`, false},
+	}
 
 	runTests(tests, t)
 }
@@ -67,11 +1227,168 @@ func TestFunctions(t *testing.T) {
 			`func one() Int {
 				return "test";
 			}`, false},
+		{
+			`func add(x Int, y Int) Int {
+				return x + y;
+			}
+
+			let x = add(1, 3);`, true},
 	}
 
 	runTests(tests, t)
 }
 
+func TestLoops(t *testing.T) {
+	tests := []Test{
+		{
+			`let x = 5;
+			while x > 0 {
+				x = x - 1;
+			}`, true},
+		{
+			`while 5 {
+				PRINT(5);
+			}`, false},
+		{
+			`while true {
+				x = 5;
+			}`, false},
+		{
+			`for i in 0..10 {
+				PRINT(i);
+			}`, true},
+		{
+			`let n = 4;
+			for i in 0..=n step 2 {
+				let y = i * 2;
+			}`, true},
+		{
+			`for i in 0.."10" {
+				PRINT(i);
+			}`, false},
+		{
+			`for i in 0..10 {
+				PRINT(i);
+			}
+			PRINT(i);`, false},
+		{
+			`for i in 0..10 {
+				i = "a";
+			}`, false},
+		{
+			`for i in 0..10 step 0 {
+				PRINT(i);
+			}`, false},
+		{
+			`while true {
+				if true {
+					break;
+				} else {
+					continue;
+				}
+			}`, true},
+		{`break;`, false},
+		{
+			`func f() Int {
+				continue;
+				return 1;
+			}`, false}}
+
+	runTests(tests, t)
+}
+
+func TestConditionals(t *testing.T) {
+	tests := []Test{
+		{
+			`if true {
+				PRINT(1);
+			}`, true},
+		{
+			`let x = 5;
+			if x > 10 {
+				PRINT(1);
+			} else if x > 3 {
+				PRINT(2);
+			} else if x > 1 {
+				PRINT(3);
+			} else {
+				PRINT(4);
+			}`, true},
+		{
+			`if true {
+				PRINT(1);
+			} else if 5 {
+				PRINT(2);
+			}`, false},
+		{
+			`func sign(x Int) Int {
+				if x > 0 {
+					return 1;
+				} else if 0 > x {
+					return 0 - 1;
+				} else {
+					return 0;
+				}
+			}`, true},
+		{
+			`func sign(x Int) Int {
+				if x > 0 {
+					return 1;
+				} else if 0 > x {
+					return 0 - 1;
+				}
+			}`, false},
+		{
+			`func f(x Int) Int {
+				if x > 0 {
+					return "positive";
+				}
+				return x;
+			}`, false},
+		{
+			`func f(x Int) Int {
+				while x > 0 {
+					return x;
+				}
+				return 0;
+			}`, true},
+		{
+			`func f(b Bool) Int {
+				if b {
+					return 1;
+				} else {
+					return 2;
+				}
+				let x Int = "oops";
+				return nope(x);
+			}`, false},
+		{
+			`func f(b Bool) Int {
+				return 1;
+				PRINT(y);
+			}`, false},
+		{
+			`func f(b Bool) Int {
+				if b {
+					return 1;
+				} else {
+					return 2;
+				}
+				PRINT(3);
+			}`, true},
+		{
+			`type Light enum { On, Off }
+			func f(l Light) Int {
+				match l {
+					Light::On { return 1; }
+					Light::Off { return 0; }
+				}
+				return "x";
+			}`, false}}
+
+	runTests(tests, t)
+}
+
 func runTests(tests []Test, t *testing.T) {
 	for i, test := range tests {
 		err := stringToChecker(test.src)
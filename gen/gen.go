@@ -10,6 +10,24 @@ import (
 
 var TMP_COUNT int
 
+// set while generating a method body, where class members
+// would otherwise hide functions of the same name
+var inMethod bool
+
+// generic functions are emitted once for each list of type arguments
+// they are called with, each under its own name
+type instance struct {
+	name     string
+	node     *ast.FunctionStatement
+	bindings map[string]string
+}
+
+var generics map[string]*ast.FunctionStatement
+var instances []instance
+
+// bindings of the instance being generated
+var typeArgs map[string]string
+
 func write(b *bytes.Buffer, code string, args ...interface{}) {
 	b.WriteString(fmt.Sprintf(code, args...))
 }
@@ -20,6 +38,45 @@ func check(err error) {
 	}
 }
 
+// C++ spelling of a type, e.g. List[Int] is List<Int>,
+// (Int, String) is Tuple<Int, String> and Int? is Optional<Int>
+func cppType(kind string) string {
+	if bound, ok := typeArgs[kind]; ok {
+		return cppType(bound)
+	}
+
+	if params, ret, ok := FuncTypes(kind); ok {
+		return "function<" + cppType(ret) + "(" + cppTypes(params) + ")>"
+	}
+
+	if elem, ok := OptionalElem(kind); ok {
+		return "Optional<" + cppType(elem) + ">"
+	}
+
+	if elems, ok := TupleTypes(kind); ok {
+		return "Tuple<" + cppTypes(elems) + ">"
+	}
+
+	if i := strings.Index(kind, "["); i != -1 {
+		args, _ := TypeArgs(kind, kind[:i])
+		return kind[:i] + "<" + cppTypes(args) + ">"
+	}
+	return kind
+}
+
+func cppTypes(kinds []string) string {
+	cpp := make([]string, len(kinds))
+	for i, kind := range kinds {
+		cpp[i] = cppType(kind)
+	}
+	return strings.Join(cpp, ", ")
+}
+
+// a type with the type parameters of the current instance replaced
+func concrete(kind string) string {
+	return SubstituteType(kind, typeArgs)
+}
+
 func freshTemp() string {
 	TMP_COUNT += 1
 	return fmt.Sprintf("tmp_%d", TMP_COUNT)
@@ -45,17 +102,39 @@ func gen(node ast.Node, b *bytes.Buffer) string {
 		return genFunctionStatement(node, b)
 	case *ast.IfStatement:
 		return genIfStatement(node, b)
+	case *ast.ForStatement:
+		return genForStatement(node, b)
+	case *ast.ForRangeStatement:
+		return genForRangeStatement(node, b)
+	case *ast.ForInStatement:
+		return genForInStatement(node, b)
+	case *ast.BreakStatement:
+		return genBreakStatement(node, b)
+	case *ast.ContinueStatement:
+		return genContinueStatement(node, b)
 	case *ast.ExpressionStatement:
 		return genExpressionStatement(node, b)
 	case *ast.AssignStatement:
 		return genAssignStatement(node, b)
+	case *ast.IndexAssignStatement:
+		return genIndexAssignStatement(node, b)
+	case *ast.FieldAssignStatement:
+		return genFieldAssignStatement(node, b)
+	case *ast.MatchStatement:
+		return genMatchStatement(node, b)
 	case *ast.InitStatement:
 		return genInitStatement(node, b)
+	case *ast.TupleInitStatement:
+		return genTupleInitStatement(node, b)
 	// // Expressions
 	case *ast.InfixExpression:
 		return genInfixExpression(node, b)
+	case *ast.PrefixExpression:
+		return genPrefixExpression(node, b)
 	case *ast.IntegerLiteral:
 		return genInteger(node, b)
+	case *ast.FloatLiteral:
+		return genFloat(node, b)
 	case *ast.StringLiteral:
 		return genString(node, b)
 	case *ast.Boolean:
@@ -64,6 +143,26 @@ func gen(node ast.Node, b *bytes.Buffer) string {
 		return genIdentifier(node, b)
 	case *ast.FunctionCall:
 		return genFunctionCall(node, b)
+	case *ast.CallExpression:
+		return genCallExpression(node, b)
+	case *ast.FunctionLiteral:
+		return genFunctionLiteral(node, b)
+	case *ast.ListLiteral:
+		return genListLiteral(node, b)
+	case *ast.TupleLiteral:
+		return genTupleLiteral(node, b)
+	case *ast.NoneLiteral:
+		return genNoneLiteral(node, b)
+	case *ast.MapLiteral:
+		return genMapLiteral(node, b)
+	case *ast.IndexExpression:
+		return genIndexExpression(node, b)
+	case *ast.FieldExpression:
+		return genFieldExpression(node, b)
+	case *ast.VariantExpression:
+		return genVariantExpression(node, b)
+	case *ast.MatchExpression:
+		return genMatchExpression(node, b)
 	}
 	return ""
 }
@@ -71,22 +170,144 @@ func gen(node ast.Node, b *bytes.Buffer) string {
 func genProgram(node *ast.Program, b *bytes.Buffer) string {
 	write(b, "#include <string>\n#include <iostream>\n#include \"Builtins.cpp\"\n\n")
 
-	for _, funcs := range node.Functions {
-		gen(funcs, b)
+	// methods are declared in their class and defined in source order
+	methods := map[string][]*ast.FunctionStatement{}
+	for _, decl := range node.Functions {
+		if f, ok := decl.(*ast.FunctionStatement); ok && f.Receiver != nil {
+			methods[f.Receiver.Type] = append(methods[f.Receiver.Type], f)
+		}
+	}
+
+	// every class is declared before any is defined so signatures
+	// can mention types declared later in the source
+	for _, decl := range node.Functions {
+		name := ""
+		switch decl := decl.(type) {
+		case *ast.StructStatement:
+			name = decl.Name
+		case *ast.EnumStatement:
+			name = decl.Name
+		case *ast.TypeStatement:
+			if !decl.Alias {
+				name = decl.Name
+			}
+		case *ast.InterfaceStatement:
+			name = decl.Name
+		}
+		if name != "" {
+			write(b, "class %s;\nstring show(%s x);\n\n", name, name)
+		}
 	}
 
-	write(b, "int main() {\n")
+	// classes come first so every function can use them
+	for _, decl := range node.Functions {
+		switch decl := decl.(type) {
+		case *ast.StructStatement:
+			genStructStatement(decl, methods[decl.Name], b)
+		case *ast.EnumStatement:
+			genEnumStatement(decl, methods[decl.Name], b)
+		case *ast.TypeStatement:
+			genTypeStatement(decl, methods[decl.Name], b)
+		case *ast.InterfaceStatement:
+			genInterfaceStatement(decl, b)
+		}
+	}
+
+	generics = map[string]*ast.FunctionStatement{}
+	instances = nil
+	for _, decl := range node.Functions {
+		if f, ok := decl.(*ast.FunctionStatement); ok && len(f.TypeParams) != 0 {
+			generics[f.Name] = f
+		}
+	}
+
+	var funcs bytes.Buffer
+	for _, decl := range node.Functions {
+		if f, ok := decl.(*ast.FunctionStatement); ok && len(f.TypeParams) == 0 {
+			gen(f, &funcs)
+		}
+	}
+
+	var main bytes.Buffer
+	write(&main, "int main() {\n")
 	for _, stmt := range node.Statements {
-		gen(stmt, b)
+		gen(stmt, &main)
+	}
+	write(&main, "return 0;\n}")
+
+	// instances are found while generating calls, including calls
+	// inside other instances, and are declared before any use
+	var insts bytes.Buffer
+	for i := 0; i < len(instances); i++ {
+		genInstance(instances[i], &insts)
+	}
+
+	// every function is declared up front so definition order
+	// never matters, even for mutually recursive functions
+	for _, decl := range node.Functions {
+		if f, ok := decl.(*ast.FunctionStatement); ok && f.Receiver == nil && len(f.TypeParams) == 0 {
+			write(b, "%s;\n", genSignature(f.Name, f))
+		}
+	}
+
+	for _, inst := range instances {
+		typeArgs = inst.bindings
+		write(b, "%s;\n", genSignature(inst.name, inst.node))
+		typeArgs = nil
 	}
-	write(b, "return 0;\n}")
+	write(b, "\n")
+
+	b.Write(funcs.Bytes())
+	b.Write(insts.Bytes())
+	b.Write(main.Bytes())
 	return ""
 }
 
+func genInstance(inst instance, b *bytes.Buffer) {
+	typeArgs = inst.bindings
+	write(b, "%s {\n", genSignature(inst.name, inst.node))
+	genBody(inst.node.Body, inst.node.Return, b)
+	write(b, "}\n\n")
+	typeArgs = nil
+}
+
+// the instance of a generic function for a call's type arguments
+func instantiate(node *ast.FunctionCall) instance {
+	generic := generics[node.Name]
+	bindings := map[string]string{}
+	args := make([]string, len(node.TypeArgs))
+	for i, param := range generic.TypeParams {
+		args[i] = concrete(node.TypeArgs[i])
+		bindings[param.Name] = args[i]
+	}
+
+	name := node.Name + "__" + strings.Map(func(c rune) rune {
+		if c == '[' || c == ']' || c == '(' || c == ')' || c == ',' || c == '?' {
+			return '_'
+		}
+		return c
+	}, strings.Join(args, "_"))
+	name = strings.Replace(name, " ", "", -1)
+
+	for _, inst := range instances {
+		if inst.name == name {
+			return inst
+		}
+	}
+
+	inst := instance{name, generic, bindings}
+	instances = append(instances, inst)
+	return inst
+}
+
+// each block gets its own C++ scope so a let can shadow parameters,
+// loop variables and bindings declared just outside it
 func genBlockStatement(node *ast.BlockStatement, b *bytes.Buffer) string {
+	write(b, "{\n")
 	for _, stmt := range node.Statements {
 		gen(stmt, b)
 	}
+	write(b, "}\n")
 	return ""
 }
 
@@ -103,14 +324,68 @@ func genAssignStatement(node *ast.AssignStatement, b *bytes.Buffer) string {
 	return ""
 }
 
+func genIndexAssignStatement(node *ast.IndexAssignStatement, b *bytes.Buffer) string {
+	right := gen(node.Right, b)
+	if MethodExist(concrete(node.Left.Type), SET) { // maps insert missing keys
+		left := genLValue(node.Left.Left, b)
+		index := gen(node.Left.Index, b)
+		write(b, "%s.%s(%s, %s);\n", left, SET, index, right)
+		return ""
+	}
+
+	left := genLValue(node.Left, b)
+	write(b, "%s = %s;\n", left, right)
+	return ""
+}
+
+func genFieldAssignStatement(node *ast.FieldAssignStatement, b *bytes.Buffer) string {
+	right := gen(node.Right, b)
+	left := genLValue(node.Left, b)
+	write(b, "%s = %s;\n", left, right)
+	return ""
+}
+
+// INDEX returns a reference, so chained indexing and
+// field access into a variable can be assigned to
+func genLValue(node ast.Expression, b *bytes.Buffer) string {
+	switch node := node.(type) {
+	case *ast.IndexExpression:
+		left := genLValue(node.Left, b)
+		index := gen(node.Index, b)
+		return fmt.Sprintf("%s.%s(%s)", left, INDEX, index)
+	case *ast.FieldExpression:
+		left := genLValue(node.Left, b)
+		return fmt.Sprintf("%s.%s", left, node.Field)
+	}
+	return gen(node, b)
+}
+
 func genInitStatement(node *ast.InitStatement, b *bytes.Buffer) string {
 	right := gen(node.Expr, b)
-	kind, _ := GetIdentType(node.Location)
-	write(b, "%s %s = %s;\n", kind, node.Location, right)
+	write(b, "%s %s = %s;\n", cppType(node.Type), node.Location, right)
+	return ""
+}
+
+func genTupleInitStatement(node *ast.TupleInitStatement, b *bytes.Buffer) string {
+	value := gen(node.Expr, b)
+	elems, _ := TupleTypes(concrete(node.Type))
+
+	tmp := freshTemp()
+	write(b, "%s %s = %s;\n", cppType(node.Type), tmp, value)
+	for i, location := range node.Locations {
+		if location != "_" {
+			write(b, "%s %s = get<%d>(%s.vals);\n", cppType(elems[i]), location, i, tmp)
+		}
+	}
 	return ""
 }
 
 func genReturnStatement(node *ast.ReturnStatement, b *bytes.Buffer) string {
+	if node.ReturnValue == nil {
+		write(b, "return %s();\n", NOTHING_TYPE)
+		return ""
+	}
+
 	value := gen(node.ReturnValue, b)
 	write(b, "return %s;\n", value)
 	return ""
@@ -121,68 +396,428 @@ func genFunctionStatement(node *ast.FunctionStatement, b *bytes.Buffer) string {
 		panic("built in function")
 	}
 
-	write(b, "%s %s(", node.Return, node.Name)
+	if node.Receiver == nil {
+		write(b, "%s {\n", genSignature(node.Name, node))
+	} else {
+		// methods take their receiver by value like any other argument
+		name := node.Receiver.Type + "::" + node.Name
+		write(b, "%s {\n", genSignature(name, node))
+		write(b, "%s %s = *this;\n", cppType(node.Receiver.Type), node.Receiver.Arg)
+	}
+
+	inMethod = node.Receiver != nil
+	genBody(node.Body, node.Return, b)
+	inMethod = false
+	write(b, "}\n\n")
+	return ""
+}
+
+// procedures may run off the end of their body, so they
+// return Nothing there like PRINT does
+func genBody(body *ast.BlockStatement, ret string, b *bytes.Buffer) {
+	gen(body, b)
+	if concrete(ret) == NOTHING_TYPE {
+		write(b, "return %s();\n", NOTHING_TYPE)
+	}
+}
 
+func genSignature(name string, node *ast.FunctionStatement) string {
+	params := make([]string, len(node.Parameters))
 	for i, arg := range node.Parameters {
-		write(b, "%s %s", arg.Type, arg.Arg)
-		if i != len(node.Parameters)-1 {
-			write(b, ",")
+		params[i] = fmt.Sprintf("%s %s", cppType(arg.Type), arg.Arg)
+	}
+	return fmt.Sprintf("%s %s(%s)", cppType(node.Return), name, strings.Join(params, ","))
+}
+
+// a struct is a class with its fields, a constructor taking
+// them in order and a show overload for printing
+func genStructStatement(node *ast.StructStatement, methods []*ast.FunctionStatement, b *bytes.Buffer) string {
+	write(b, "class %s {\npublic:\n", node.Name)
+
+	params := make([]string, len(node.Fields))
+	inits := make([]string, len(node.Fields))
+	shows := make([]string, len(node.Fields))
+	for i, field := range node.Fields {
+		write(b, "%s %s;\n", cppType(field.Type), field.Arg)
+		params[i] = fmt.Sprintf("%s %s", cppType(field.Type), field.Arg)
+		inits[i] = fmt.Sprintf("%s(%s)", field.Arg, field.Arg)
+		shows[i] = fmt.Sprintf("\"%s: \" + show(x.%s)", field.Arg, field.Arg)
+	}
+
+	write(b, "%s(%s)", node.Name, strings.Join(params, ", "))
+	if len(inits) != 0 {
+		write(b, " : %s", strings.Join(inits, ", "))
+	}
+	write(b, " {}\n")
+	for _, method := range methods {
+		write(b, "%s;\n", genSignature(method.Name, method))
+	}
+	write(b, "Nothing PRINT() {\ncout << ::show(*this) << endl;\nreturn Nothing();\n}\n};\n\n")
+
+	write(b, "string show(%s x) {\n", node.Name)
+	if len(shows) == 0 {
+		write(b, "return \"%s()\";\n}\n\n", node.Name)
+	} else {
+		write(b, "return string(\"%s(\") + %s + \")\";\n}\n\n", node.Name, strings.Join(shows, " + \", \" + "))
+	}
+	return ""
+}
+
+// an interface value holds any type satisfying it behind a pointer to an
+// abstract class, Model_ forwards each method to the held value
+func genInterfaceStatement(node *ast.InterfaceStatement, b *bytes.Buffer) string {
+	write(b, "class %s {\npublic:\n", node.Name)
+
+	write(b, "struct Concept_ {\nvirtual ~Concept_() {}\n")
+	for _, spec := range node.Methods {
+		write(b, "virtual %s = 0;\n", genSpecSignature(spec))
+	}
+	write(b, "virtual string show_() = 0;\n};\n\n")
+
+	write(b, "template <typename T>\nstruct Model_ : Concept_ {\nT value_;\nModel_(T x) : value_(x) {}\n")
+	for _, spec := range node.Methods {
+		write(b, "%s {\nreturn value_.%s(%s);\n}\n", genSpecSignature(spec), spec.Name, specArgs(spec))
+	}
+	write(b, "string show_() {\nreturn ::showValue(value_);\n}\n};\n\n")
+
+	write(b, "shared_ptr<Concept_> self_;\n")
+	write(b, "template <typename T, typename = enable_if_t<!is_same_v<decay_t<T>, %s>>>\n", node.Name)
+	write(b, "%s(T x) : self_(make_shared<Model_<T>>(x)) {}\n", node.Name)
+	for _, spec := range node.Methods {
+		write(b, "%s {\nreturn self_->%s(%s);\n}\n", genSpecSignature(spec), spec.Name, specArgs(spec))
+	}
+	write(b, "Nothing PRINT() {\ncout << ::show(*this) << endl;\nreturn Nothing();\n}\n};\n\n")
+
+	write(b, "string show(%s x) {\nreturn x.self_->show_();\n}\n\n", node.Name)
+	return ""
+}
+
+func genSpecSignature(spec ast.MethodSpec) string {
+	params := make([]string, len(spec.Parameters))
+	for i, arg := range spec.Parameters {
+		params[i] = fmt.Sprintf("%s %s", cppType(arg.Type), arg.Arg)
+	}
+	return fmt.Sprintf("%s %s(%s)", cppType(spec.Return), spec.Name, strings.Join(params, ", "))
+}
+
+func specArgs(spec ast.MethodSpec) string {
+	args := make([]string, len(spec.Parameters))
+	for i, arg := range spec.Parameters {
+		args[i] = arg.Arg
+	}
+	return strings.Join(args, ", ")
+}
+
+// an alias is a typedef, a newtype is a class deriving from its base
+// that converts from the base and prints like it
+func genTypeStatement(node *ast.TypeStatement, methods []*ast.FunctionStatement, b *bytes.Buffer) string {
+	base := cppType(node.Type)
+	if node.Alias {
+		write(b, "typedef %s %s;\n\n", base, node.Name)
+		return ""
+	}
+
+	write(b, "class %s : public %s {\npublic:\n", node.Name, base)
+	write(b, "%s(%s x) : %s(x) {}\n", node.Name, base, base)
+	for _, method := range methods {
+		write(b, "%s;\n", genSignature(method.Name, method))
+	}
+	write(b, "Nothing PRINT() {\ncout << ::show(*this) << endl;\nreturn Nothing();\n}\n};\n\n")
+	write(b, "string show(%s x) {\nreturn show(%s(x));\n}\n\n", node.Name, base)
+	return ""
+}
+
+// an enum is a class with a tag and a vector holding each payload value
+// of the current variant, variants are built by static methods
+func genEnumStatement(node *ast.EnumStatement, methods []*ast.FunctionStatement, b *bytes.Buffer) string {
+	write(b, "class %s {\npublic:\nint tag;\n", node.Name)
+	for _, variant := range node.Variants {
+		for i, kind := range variant.Types {
+			write(b, "vector<%s> %s_%d;\n", cppType(kind), variant.Name, i)
+		}
+	}
+	write(b, "%s(int tag) : tag(tag) {}\n", node.Name)
+
+	for tag, variant := range node.Variants {
+		params := make([]string, len(variant.Types))
+		for i, kind := range variant.Types {
+			params[i] = fmt.Sprintf("%s _%d", cppType(kind), i)
+		}
+
+		write(b, "static %s %s(%s) {\n%s x(%d);\n", node.Name, variant.Name, strings.Join(params, ", "), node.Name, tag)
+		for i := range variant.Types {
+			write(b, "x.%s_%d.push_back(_%d);\n", variant.Name, i, i)
+		}
+		write(b, "return x;\n}\n")
+	}
+
+	for _, method := range methods {
+		write(b, "%s;\n", genSignature(method.Name, method))
+	}
+
+	if MethodExist(node.Name, EQUAL) {
+		write(b, "Bool EQ(%s o) {\nif (tag != o.tag) {\nreturn Bool(False);\n}\n", node.Name)
+		for tag, variant := range node.Variants {
+			for i := range variant.Types {
+				field := fmt.Sprintf("%s_%d[0]", variant.Name, i)
+				write(b, "if (tag == %d && %s.EQ(o.%s).val == False) {\nreturn Bool(False);\n}\n", tag, field, field)
+			}
+		}
+		write(b, "return Bool(True);\n}\n")
+		write(b, "Bool NE(%s o) {\nreturn EQ(o).NOT();\n}\n", node.Name)
+	}
+	write(b, "Nothing PRINT() {\ncout << ::show(*this) << endl;\nreturn Nothing();\n}\n};\n\n")
+
+	// printed the way they are written, e.g. Shape::Circle(1.0)
+	write(b, "string show(%s x) {\n", node.Name)
+	for tag, variant := range node.Variants {
+		text := fmt.Sprintf("\"%s::%s\"", node.Name, variant.Name)
+		if len(variant.Types) != 0 {
+			shows := make([]string, len(variant.Types))
+			for i := range variant.Types {
+				shows[i] = fmt.Sprintf("show(x.%s_%d[0])", variant.Name, i)
+			}
+			text = fmt.Sprintf("string(\"%s::%s(\") + %s + \")\"", node.Name, variant.Name, strings.Join(shows, " + \", \" + "))
+		}
+
+		if tag == len(node.Variants)-1 {
+			write(b, "return %s;\n", text)
+		} else {
+			write(b, "if (x.tag == %d) {\nreturn %s;\n}\n", tag, text)
 		}
 	}
-	write(b, ") {\n")
-	gen(node.Body, b)
 	write(b, "}\n\n")
 	return ""
 }
 
+func genMatchStatement(node *ast.MatchStatement, b *bytes.Buffer) string {
+	value := gen(node.Value, b)
+	genMatchArms(node.Type, value, node.Arms, b, func(arm ast.MatchArm) {
+		gen(arm.Block, b)
+	})
+	return ""
+}
+
+// the arms run inside a lambda so each can return its value
+func genMatchExpression(node *ast.MatchExpression, b *bytes.Buffer) string {
+	value := gen(node.Value, b)
+
+	tmp := freshTemp()
+	kind := cppType(node.Result)
+	write(b, "%s %s = [&]() -> %s {\n", kind, tmp, kind)
+	genMatchArms(node.Type, value, node.Arms, b, func(arm ast.MatchArm) {
+		result := gen(arm.Value, b)
+		write(b, "return %s;\n", result)
+	})
+	write(b, "}();\n")
+	return tmp
+}
+
+// the checker makes sure arms are exhaustive, so the last one needs no test
+func genMatchArms(kind, value string, arms []ast.MatchArm, b *bytes.Buffer, body func(ast.MatchArm)) {
+	// copied so bindings may shadow the matched variable
+	subject := freshTemp()
+	write(b, "%s %s = %s;\n", kind, subject, value)
+	value = subject
+
+	for i, arm := range arms {
+		variant, tag, _ := LookupVariant(kind, arm.Pattern.Variant)
+		last := i == len(arms)-1
+		switch {
+		case i == 0 && last:
+			write(b, "{\n")
+		case i == 0:
+			write(b, "if (%s.tag == %d) {\n", value, tag)
+		case last:
+			write(b, "} else {\n")
+		default:
+			write(b, "} else if (%s.tag == %d) {\n", value, tag)
+		}
+
+		for j, name := range arm.Pattern.Bindings {
+			if name != "_" {
+				write(b, "%s %s = %s.%s_%d[0];\n", cppType(variant.Types[j]), name, value, variant.Name, j)
+			}
+		}
+		body(arm)
+	}
+	write(b, "}\n\n")
+}
+
 func genIfStatement(node *ast.IfStatement, b *bytes.Buffer) string {
 	cond := gen(node.Condition, b)
-	write(b, "if (\"true\" == %s.val) {\n", cond)
+	if node.Binding != "" {
+		// copied so the binding may shadow the optional variable
+		tmp := freshTemp()
+		elem, _ := OptionalElem(concrete(node.Type))
+		write(b, "%s %s = %s;\n", cppType(node.Type), tmp, cond)
+		write(b, "if (%s.HAS()) {\n%s %s = %s.value[0];\n", tmp, cppType(elem), node.Binding, tmp)
+	} else {
+		write(b, "if (\"true\" == %s.val) {\n", cond)
+	}
 	gen(node.Block, b)
-	write(b, "} else {\n")
-	gen(node.Alternative, b)
+	// else if chains are nested inside the else block so their
+	// condition temps are only evaluated when reached
+	if node.Alternative != nil {
+		write(b, "} else {\n")
+		gen(node.Alternative, b)
+	}
 	write(b, "}\n\n")
 	return ""
 }
 
+// condition temps are generated inside the loop
+// so they are re-evaluated on every iteration
+func genForStatement(node *ast.ForStatement, b *bytes.Buffer) string {
+	write(b, "while (true) {\n")
+	cond := gen(node.Condition, b)
+	write(b, "if (\"true\" != %s.val) {\nbreak;\n}\n", cond)
+	gen(node.BlockStatement, b)
+	write(b, "}\n\n")
+	return ""
+}
+
+// bounds are evaluated once and the loop counts over a raw int,
+// wrapping it in an Int for the body
+func genForRangeStatement(node *ast.ForRangeStatement, b *bytes.Buffer) string {
+	start := gen(node.Start, b)
+	// the end and step are evaluated once, before the loop
+	value := gen(node.End, b)
+	end := freshTemp()
+	write(b, "int %s = %s.valInt;\n", end, value)
+
+	lt, gt := "<", ">"
+	if node.Inclusive {
+		lt, gt = "<=", ">="
+	}
+
+	step := "1"
+	if node.Step != nil {
+		value := gen(node.Step, b)
+		step = freshTemp()
+		write(b, "int %s = %s.valInt;\n", step, value)
+		write(b, "if (%s == 0) {\nruntimeError(\"range step cannot be zero\");\n}\n", step)
+	}
+
+	counter := freshTemp()
+	cond := fmt.Sprintf("%s %s %s", counter, lt, end)
+	if node.Step != nil {
+		// a negative step counts down
+		cond = fmt.Sprintf("(%s > 0 ? %s %s %s : %s %s %s)", step, counter, lt, end, counter, gt, end)
+	}
+
+	write(b, "for (int %s = %s.valInt; %s; %s += %s) {\n", counter, start, cond, counter, step)
+	write(b, "Int %s = Int(%s);\n", node.Iterator, counter)
+	gen(node.Block, b)
+	write(b, "}\n\n")
+	return ""
+}
+
+// iterates over a copy, so the body may modify the collection
+func genForInStatement(node *ast.ForInStatement, b *bytes.Buffer) string {
+	iterable := gen(node.Iterable, b)
+	write(b, "for (%s %s : %s.%s()) {\n", cppType(node.Type), node.Iterator, iterable, ITER)
+	gen(node.Block, b)
+	write(b, "}\n\n")
+	return ""
+}
+
+func genBreakStatement(node *ast.BreakStatement, b *bytes.Buffer) string {
+	write(b, "break;\n")
+	return ""
+}
+
+// while loops re-evaluate their condition at the top of the loop body,
+// so jumping back there is enough for both loop forms
+func genContinueStatement(node *ast.ContinueStatement, b *bytes.Buffer) string {
+	write(b, "continue;\n")
+	return ""
+}
+
 func genInteger(node *ast.IntegerLiteral, b *bytes.Buffer) string {
 	tmp := freshTemp()
-	write(b, "Int %s = Int(%s);\n", tmp, string(node.Token.Lit))
+	write(b, "Int %s = Int(%s);\n", tmp, node.Value)
+	return tmp
+}
+
+func genFloat(node *ast.FloatLiteral, b *bytes.Buffer) string {
+	tmp := freshTemp()
+	write(b, "Float %s = Float(%s);\n", tmp, node.Value)
 	return tmp
 }
 
 func genString(node *ast.StringLiteral, b *bytes.Buffer) string {
 	tmp := freshTemp()
-	str := string(node.Token.Lit)
-	str = strings.Replace(str, `\`, "\\", -1)
+	str := cppString(node.Value)
+
+	// a C string would stop at an embedded NUL
+	if strings.ContainsRune(node.Value, 0) {
+		str = fmt.Sprintf("string(%s, %d)", str, len(node.Value))
+	}
 
 	write(b, "String %s = String(%s);\n", tmp, str)
 	return tmp
 }
 
+// quote a decoded string as a C++ literal, anything outside of
+// printable ASCII is written byte by byte as an octal escape
+func cppString(s string) string {
+	var out bytes.Buffer
+	out.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		case c < ' ' || c > '~':
+			write(&out, "\\%03o", c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
+
 func genBoolean(node *ast.Boolean, b *bytes.Buffer) string {
 	if node.Value {
 		return "Bool(\"true\")"
 	} else {
 		return "Bool(\"false\")"
 	}
-	return ""
 }
 
 func genIdentifier(node *ast.Identifier, b *bytes.Buffer) string {
+	if node.Function {
+		return "::" + node.Value
+	}
 	return node.Value
 }
 
 func genInfixExpression(node *ast.InfixExpression, b *bytes.Buffer) string {
 	left := gen(node.Left, b)
 	right := gen(node.Right, b)
-	kind := node.Type
+	kind := concrete(node.Type)
 
 	tmp := freshTemp()
-	methods := map[string]string{"+": PLUS, "-": MINUS, "==": EQUAL, "<": LT, ">": GT, "*": TIMES, "or": OR, "and": AND}
+	method, _ := GetMethod(kind, Operators[node.Operator])
+	write(b, "%s %s = %s.%s(%s);\n", cppType(method.Return), tmp, left, Operators[node.Operator], right)
+	return tmp
+}
+
+func genPrefixExpression(node *ast.PrefixExpression, b *bytes.Buffer) string {
+	right := gen(node.Right, b)
+	kind := concrete(node.Type)
 
-	method, _ := GetMethod(kind, methods[node.Operator])
-	write(b, "%s %s = %s.%s(%s);\n", method.Return, tmp, left, methods[node.Operator], right)
+	tmp := freshTemp()
+	method, _ := GetMethod(kind, PrefixOperators[node.Operator])
+	write(b, "%s %s = %s.%s();\n", cppType(method.Return), tmp, right, PrefixOperators[node.Operator])
 	return tmp
 }
 
@@ -191,21 +826,51 @@ func genFunctionCall(node *ast.FunctionCall, b *bytes.Buffer) string {
 	args := make([]string, len(node.Args))
 	// store expression tmp vars
 	for i, arg := range node.Args {
+		if i == 0 && IsMethodCall(node) {
+			args[i] = genLValue(arg, b) // builtins like APPEND modify their receiver
+			continue
+		}
 		res := gen(arg, b)
 		args[i] = res
 	}
 
 	tmp := freshTemp()
-	if IsBuiltin(node.Name) {
-		sig, ok := GetMethod(node.Type, node.Name)
+	if IsMethodCall(node) {
+		sig, ok := GetMethod(concrete(node.Type), node.Name)
 		if !ok {
-			panic("no builtin function")
+			// a struct field holding a function
+			field, _ := FieldType(concrete(node.Type), node.Name)
+			_, sig.Return, _ = FuncTypes(field)
 		}
 
-		write(b, "%s %s = %s.%s(", sig.Return, tmp, args[0], node.Name)
+		write(b, "%s %s = %s.%s(", cppType(sig.Return), tmp, args[0], node.Name)
+		for i, arg := range args[1:] {
+			write(b, arg)
+			if i != len(args)-2 {
+				write(b, ",")
+			}
+		}
 	} else {
-		sig, _ = GetFunctionSignature(node.Name)
-		write(b, "%s %s = %s(", sig.Return, tmp, node.Name)
+		// functions are qualified so neither class members nor argument
+		// dependent lookup in std can be picked instead
+		name := "::" + node.Name
+		var ok bool
+		if node.Value {
+			_, sig.Return, _ = FuncTypes(concrete(node.Type))
+			name = node.Name
+		} else if sig, ok = GetFunctionSignature(node.Name); !ok {
+			// conversions call the constructor of the target type
+			sig.Return = concrete(node.Type)
+			name = cppType(node.Type)
+			if inMethod {
+				name = "::" + name
+			}
+		} else if len(node.TypeArgs) != 0 {
+			inst := instantiate(node)
+			sig.Return = SubstituteType(inst.node.Return, inst.bindings)
+			name = "::" + inst.name
+		}
+		write(b, "%s %s = %s(", cppType(sig.Return), tmp, name)
 		for i, arg := range args {
 			write(b, arg)
 			if i != len(args)-1 {
@@ -217,3 +882,111 @@ func genFunctionCall(node *ast.FunctionCall, b *bytes.Buffer) string {
 	write(b, ");")
 	return tmp
 }
+
+// captures are copied so the lambda can outlive the scope it was made
+// in, mutable lets it call methods on its copies
+func genFunctionLiteral(node *ast.FunctionLiteral, b *bytes.Buffer) string {
+	var body bytes.Buffer
+	genBody(node.Body, node.Return, &body)
+
+	params := make([]string, len(node.Parameters))
+	for i, arg := range node.Parameters {
+		params[i] = fmt.Sprintf("%s %s", cppType(arg.Type), arg.Arg)
+	}
+
+	tmp := freshTemp()
+	write(b, "%s %s = [%s](%s) mutable -> %s {\n", cppType(node.Type), tmp, strings.Join(node.Captures, ", "), strings.Join(params, ", "), cppType(node.Return))
+	b.Write(body.Bytes())
+	write(b, "};\n")
+	return tmp
+}
+
+func genCallExpression(node *ast.CallExpression, b *bytes.Buffer) string {
+	function := gen(node.Function, b)
+	args := make([]string, len(node.Args))
+	for i, arg := range node.Args {
+		args[i] = gen(arg, b)
+	}
+
+	tmp := freshTemp()
+	_, ret, _ := FuncTypes(concrete(node.Type))
+	write(b, "%s %s = %s(%s);\n", cppType(ret), tmp, function, strings.Join(args, ", "))
+	return tmp
+}
+
+func genNoneLiteral(node *ast.NoneLiteral, b *bytes.Buffer) string {
+	tmp := freshTemp()
+	kind := cppType(node.Type)
+	write(b, "%s %s = %s();\n", kind, tmp, kind)
+	return tmp
+}
+
+func genTupleLiteral(node *ast.TupleLiteral, b *bytes.Buffer) string {
+	elems := make([]string, len(node.Elements))
+	for i, elem := range node.Elements {
+		elems[i] = gen(elem, b)
+	}
+
+	tmp := freshTemp()
+	kind := cppType(node.Type)
+	write(b, "%s %s = %s(%s);\n", kind, tmp, kind, strings.Join(elems, ", "))
+	return tmp
+}
+
+func genListLiteral(node *ast.ListLiteral, b *bytes.Buffer) string {
+	elems := make([]string, len(node.Elements))
+	for i, elem := range node.Elements {
+		elems[i] = gen(elem, b)
+	}
+
+	tmp := freshTemp()
+	kind := cppType(node.Type)
+	if len(elems) == 0 {
+		write(b, "%s %s = %s();\n", kind, tmp, kind)
+	} else {
+		write(b, "%s %s = %s({%s});\n", kind, tmp, kind, strings.Join(elems, ", "))
+	}
+	return tmp
+}
+
+func genMapLiteral(node *ast.MapLiteral, b *bytes.Buffer) string {
+	tmp := freshTemp()
+	kind := cppType(node.Type)
+	write(b, "%s %s = %s();\n", kind, tmp, kind)
+	for _, entry := range node.Entries {
+		key := gen(entry.Key, b)
+		value := gen(entry.Value, b)
+		write(b, "%s.%s(%s, %s);\n", tmp, SET, key, value)
+	}
+	return tmp
+}
+
+func genIndexExpression(node *ast.IndexExpression, b *bytes.Buffer) string {
+	left := gen(node.Left, b)
+	index := gen(node.Index, b)
+
+	tmp := freshTemp()
+	method, _ := GetMethod(concrete(node.Type), INDEX)
+	write(b, "%s %s = %s.%s(%s);\n", cppType(method.Return), tmp, left, INDEX, index)
+	return tmp
+}
+
+func genFieldExpression(node *ast.FieldExpression, b *bytes.Buffer) string {
+	left := gen(node.Left, b)
+
+	tmp := freshTemp()
+	kind, _ := FieldType(concrete(node.Type), node.Field)
+	write(b, "%s %s = %s.%s;\n", cppType(kind), tmp, left, node.Field)
+	return tmp
+}
+
+func genVariantExpression(node *ast.VariantExpression, b *bytes.Buffer) string {
+	args := make([]string, len(node.Args))
+	for i, arg := range node.Args {
+		args[i] = gen(arg, b)
+	}
+
+	tmp := freshTemp()
+	write(b, "%s %s = %s::%s(%s);\n", node.Enum, tmp, node.Enum, node.Variant, strings.Join(args, ", "))
+	return tmp
+}
@@ -79,14 +79,17 @@ func TestGen(t *testing.T) {
 				#include <string>
 				#include <iostream>
 				#include "Builtins.cpp"
-				Int add(Int y, Int x) {
+				Int add(Int x, Int y);
+				Int add(Int x, Int y) {
+					{
 					Int tmp_1 = x.PLUS(y);
 					return tmp_1;
+					}
 				}
 				int main() {
-				Int tmp_2 = Int(3);
-				Int tmp_3 = Int(1);
-				Int tmp_4 = add(tmp_2, tmp_3);
+				Int tmp_2 = Int(1);
+				Int tmp_3 = Int(3);
+				Int tmp_4 = ::add(tmp_2, tmp_3);
 				Int a = tmp_4;
 				return 0;
 				}`},
@@ -106,11 +109,75 @@ func TestGen(t *testing.T) {
 				Int tmp_1 = Int(0);
 				Int x = tmp_1;
 				if("true" == Bool("true").val) {
+					{
 					Int tmp_2 = Int(5);
 					x = tmp_2;
+					}
 				} else {
+					{
 					Int tmp_3 = Int(6);
 					x = tmp_3;
+					}
+				}
+				return 0;
+				}`},
+		{
+			src: `
+				let x = 3;
+				while x > 0 {
+					x = x - 1;
+				}`,
+			res: `
+				#include <string>
+				#include <iostream>
+				#include "Builtins.cpp"
+				int main() {
+				Int tmp_1 = Int(3);
+				Int x = tmp_1;
+				while (true) {
+					Int tmp_2 = Int(0);
+					Bool tmp_3 = x.GT(tmp_2);
+					if ("true" != tmp_3.val) {
+						break;
+					}
+					{
+					Int tmp_4 = Int(1);
+					Int tmp_5 = x.MINUS(tmp_4);
+					x = tmp_5;
+					}
+				}
+				return 0;
+				}`},
+		{
+			src: `let s = "a\tb\"c\u{e9}\\";`,
+			res: `
+				#include <string>
+				#include <iostream>
+				#include "Builtins.cpp"
+				int main() {
+				String tmp_1 = String("a\tb\"c\303\251\\");
+				String s = tmp_1;
+				return 0;
+				}`},
+		{
+			src: `
+				for i in 0..3 {
+					PRINT(i);
+				}`,
+			res: `
+				#include <string>
+				#include <iostream>
+				#include "Builtins.cpp"
+				int main() {
+				Int tmp_1 = Int(0);
+				Int tmp_2 = Int(3);
+				int tmp_3 = tmp_2.valInt;
+				for (int tmp_4 = tmp_1.valInt; tmp_4 < tmp_3; tmp_4 += 1) {
+					Int i = Int(tmp_4);
+					{
+					Nothing tmp_5 = i.PRINT();
+					tmp_5;
+					}
 				}
 				return 0;
 				}`}}
@@ -171,7 +238,871 @@ func TestOutPut(t *testing.T) {
 				} else {
 					x = 6;
 				}`,
-			out: ""}}
+			out: ""},
+		{
+			src: `
+				let x = 3;
+				while x > 0 {
+					PRINT(x);
+					x = x - 1;
+				}`,
+			out: "321"},
+		{
+			src: `
+				for i in 0..3 {
+					PRINT(i);
+				}`,
+			out: "012"},
+		{
+			src: `
+				for i in 1..=3 {
+					PRINT(i);
+				}`,
+			out: "123"},
+		{
+			src: `
+				for i in 0..10 step 3 {
+					PRINT(i);
+				}`,
+			out: "0369"},
+		{
+			src: `
+				for i in 5..0 step 0 - 2 {
+					PRINT(i);
+				}`,
+			out: "531"},
+		{
+			src: `
+				let x = 0;
+				while 10 > x {
+					x = x + 1;
+					if 3 > x {
+						continue;
+					} else {
+					}
+					if x > 4 {
+						break;
+					} else {
+					}
+					PRINT(x);
+				}`,
+			out: "34"},
+		{
+			src: `
+				for i in 0..5 {
+					if 2 > i {
+						continue;
+					} else {
+					}
+					if i > 3 {
+						break;
+					} else {
+					}
+					PRINT(i);
+				}`,
+			out: "23"},
+		{
+			src: `
+				if true {
+					PRINT(1);
+				}
+				if false {
+					PRINT(2);
+				}`,
+			out: "1"},
+		{
+			src: `
+				func grade(x Int) String {
+					if x > 89 {
+						return "A";
+					} else if x > 79 {
+						return "B";
+					} else if x > 69 {
+						return "C";
+					} else {
+						return "F";
+					}
+				}
+
+				PRINT(grade(95));
+				PRINT(grade(85));
+				PRINT(grade(75));
+				PRINT(grade(5));`,
+			out: "ABCF"},
+		{
+			src: `
+				PRINT(2 + 3 * 4);
+				PRINT(2 * 3 + 4);
+				PRINT(10 - 4 - 3);
+				PRINT(3 > 1 + 1);
+				PRINT(1 > 2 or 3 > 2 and 2 > 1);
+				PRINT(false and true or true);`,
+			out: "14103truetruetrue"},
+		{
+			src: `
+				let x = 4;
+				PRINT(-5);
+				PRINT(3 - -2);
+				PRINT(-x * 2);
+				PRINT(not true);
+				PRINT(not (x > 5));`,
+			out: "-55-8falsetrue"},
+		{
+			src: `
+				for i in 3..-3 step -2 {
+					PRINT(i);
+				}`,
+			out: "31-1"},
+		{
+			src: `
+				let n = 3;
+				for i in 0..n {
+					n = 1;
+					PRINT(i);
+				}
+				let s = 1;
+				for i in 0..6 step s {
+					s = 5;
+					PRINT(i);
+				}`,
+			out: "012012345"},
+		{
+			src: `
+				let n = 1;
+				for i in n+1..5 {
+					PRINT(i);
+				}
+				for i in -1..3 {
+					PRINT(i);
+				}
+				for i in n-1..=1 {
+					PRINT(i);
+				}
+				PRINT(0.5 + 1.25);`,
+			out: "234-1012011.75"},
+		{
+			src: `
+				PRINT(7 / 2);
+				PRINT(-7 / 2);
+				PRINT(7 % 3);
+				PRINT(-7 % 3);
+				PRINT(2 < 3);
+				PRINT(3 < 3);
+				PRINT(3 <= 3);
+				PRINT(2 >= 3);
+				PRINT(3 >= 3);
+				PRINT(3 == 3);
+				PRINT(3 != 3);`,
+			out: "3-31-1truefalsetruefalsetruetruefalse"},
+		{
+			src: `
+				let n = 0;
+				let i = 1;
+				while i <= 10 {
+					if i % 2 == 0 {
+						n = n + i;
+					}
+					i = i + 1;
+				}
+				PRINT(n);`,
+			out: "30"},
+		{
+			src: `
+				let name = "Jeff";
+				PRINT(name == "Jeff");
+				PRINT(name != "Jeff");
+				PRINT("apple" < "banana");
+				PRINT("apple" > "apples");
+				PRINT("b" <= "b");
+				PRINT("a" >= "b");
+				PRINT(true == (1 < 2));
+				PRINT(true != false);`,
+			out: "truefalsetruefalsetruefalsetruetrue"},
+		{
+			src: `
+				PRINT(1.5 + 2.25);
+				PRINT(0.1 + 0.2);
+				PRINT(2.0 * 3.0);
+				PRINT(1.0 / 4.0);
+				PRINT(-2.5e3);
+				PRINT(1.5 < 2.5);
+				PRINT(FLOAT(7) / 2.0);
+				PRINT(INT(-3.9));
+				PRINT(INT(2.5e2) + 1);
+				PRINT(0.1);
+				PRINT(0.1 + 0.2 == 0.3);`,
+			out: "3.750.300000000000000046.00.25-2500.0true3.5-32510.1false"},
+		{
+			src: `
+				PRINT("a, b.");
+				PRINT("x: 1; y = [2]");
+				PRINT("say \"hi\"");
+				PRINT("back\\slash");
+				PRINT("caf\u{e9}" + "!");
+				PRINT("日本");`,
+			out: `a,b.x:1;y=[2]say"hi"back\slashcafé!日本`},
+		{
+			src: `
+				func sub(a Int, b Int) Int {
+					return a - b;
+				}
+				PRINT(sub(5, 3));`,
+			out: "2"},
+		{
+			src: `
+				let xs = [1, 2, 3];
+				PRINT(xs);
+				PRINT(xs[1]);
+				xs[1] = 20;
+				APPEND(xs, 4);
+				PRINT(LEN(xs));
+				for x in xs {
+					PRINT(x);
+				}`,
+			out: "[1,2,3]2412034"},
+		{
+			src: `
+				let grid List[List[String]] = [];
+				APPEND(grid, ["a", "b"]);
+				APPEND(grid, []);
+				grid[1] = ["c"];
+				grid[0][1] = "z";
+				PRINT(grid);
+				let copy = grid;
+				copy[0][0] = "changed";
+				PRINT(grid[0][0]);`,
+			out: "[[a,z],[c]]a"},
+		{
+			src: `
+				func evens(n Int) List[Int] {
+					let xs List[Int] = [];
+					for i in 0..n {
+						if i % 2 == 0 {
+							APPEND(xs, i);
+						}
+					}
+					return xs;
+				}
+				PRINT(evens(7));`,
+			out: "[0,2,4,6]"},
+		{
+			src: `
+				let m = {"b": 2, "a": 1};
+				m["c"] = 3;
+				m["b"] = 20;
+				PRINT(m);
+				PRINT(m["b"] + LEN(m));
+				PRINT(HAS(m, "a"));
+				DELETE(m, "a");
+				DELETE(m, "z");
+				PRINT(HAS(m, "a"));
+				for k in m {
+					PRINT(k);
+				}`,
+			out: "{b:20,a:1,c:3}23truefalsebc"},
+		{
+			src: `
+				func count(words List[String]) Map[String, Int] {
+					let counts Map[String, Int] = {};
+					for w in words {
+						if HAS(counts, w) {
+							counts[w] = counts[w] + 1;
+						} else {
+							counts[w] = 1;
+						}
+					}
+					return counts;
+				}
+				let groups = {1: ["x"]};
+				groups[1][0] = "y";
+				PRINT(groups);
+				PRINT(count(["a", "b", "a"]));`,
+			out: "{1:[y]}{a:2,b:1}"},
+		{
+			src: `
+				type Point struct { x Int, y Int }
+				type Player struct { name String, pos Point, scores List[Int] }
+				func move(p Player, dx Int) Player {
+					p.pos.x = p.pos.x + dx;
+					return p;
+				}
+				let p = Player("ann", Point(1, 2), [3]);
+				let q = move(p, 10);
+				q.scores[0] = 4;
+				APPEND(q.scores, 5);
+				PRINT(p);
+				PRINT(q.pos.x + q.pos.y);
+				PRINT(q.scores);`,
+			out: "Player(name:ann,pos:Point(x:1,y:2),scores:[3])13[4,5]"},
+		{
+			src: `
+				type Empty struct {}
+				type Point struct { x Int, y Int }
+				let ps = [Point(1, 2), Point(3, 4)];
+				ps[1].x = 30;
+				let total = 0;
+				for p in ps {
+					total = total + p.x;
+				}
+				PRINT(total);
+				PRINT(ps);
+				PRINT(Empty());`,
+			out: "31[Point(x:1,y:2),Point(x:30,y:4)]Empty()"},
+		{
+			src: `
+				let xss = [[1], [2]];
+				APPEND(xss[1], 3);
+				let m = {"a": [1]};
+				APPEND(m["a"], 2);
+				PRINT(xss);
+				PRINT(m);`,
+			out: "[[1],[2,3]]{a:[1,2]}"},
+		{
+			src: `
+				type Point struct { x Int, y Int }
+				func (p Point) norm() Int {
+					return p.x * p.x + p.y * p.y;
+				}
+				func (p Point) add(q Point) Point {
+					return Point(p.x + q.x, p.y + q.y);
+				}
+				func (p Point) reset() Point {
+					p.x = 0;
+					return p;
+				}
+				func sum(ps List[Point]) Point {
+					let total = Point(0, 0);
+					for p in ps {
+						total = total.add(p);
+					}
+					return total;
+				}
+				let p = Point(1, 2);
+				PRINT(p.norm());
+				PRINT(sum([p, Point(3, 4)]));
+				PRINT(p.reset().x);
+				PRINT(p.x);
+				let xs = [1];
+				xs.APPEND(p.add(p).norm());
+				xs.PRINT();`,
+			out: "5Point(x:4,y:6)01[1,20]"},
+		{
+			src: `
+				type Counter struct { n Int }
+				func n(c Counter) Int {
+					return c.n;
+				}
+				func (c Counter) show() Int {
+					PRINT(c);
+					return n(c);
+				}
+				func (c Counter) next() Counter {
+					return Counter(n(c) + 1);
+				}
+				PRINT(Counter(1).next().show());`,
+			out: "Counter(n:2)2"},
+		{
+			src: `
+				type Vec struct { x Int, y Int }
+				func (a Vec) PLUS(b Vec) Vec {
+					return Vec(a.x + b.x, a.y + b.y);
+				}
+				func (a Vec) TIMES(k Int) Vec {
+					return Vec(a.x * k, a.y * k);
+				}
+				func (a Vec) EQ(b Vec) Bool {
+					return a.x == b.x and a.y == b.y;
+				}
+				func (a Vec) LT(b Vec) Bool {
+					return a.x * a.x + a.y * a.y < b.x * b.x + b.y * b.y;
+				}
+				func (a Vec) NEG() Vec {
+					return a * -1;
+				}
+				let v = Vec(1, 2) + Vec(3, 4) * 2;
+				PRINT(v);
+				PRINT(-v);
+				if v == Vec(7, 10) and Vec(1, 1) < v {
+					PRINT("equal");
+				}
+				let names = {Vec(0, 1): "up"};
+				names[Vec(0, 1) + Vec(0, 0)] = "north";
+				PRINT(names);`,
+			out: "Vec(x:7,y:10)Vec(x:-7,y:-10)equal{Vec(x:0,y:1):north}"},
+		{
+			src: `
+				type Shape enum { Circle(Int), Rect(Int, Int), Empty }
+				func area(s Shape) Int {
+					match s {
+						Shape::Circle(r) { return 3 * r * r; }
+						Shape::Rect(w, h) { return w * h; }
+						Shape::Empty { return 0; }
+					}
+				}
+				let shapes = [Shape::Circle(2), Shape::Rect(3, 4), Shape::Empty];
+				for s in shapes {
+					PRINT(area(s));
+				}
+				for s in shapes {
+					match s {
+						Shape::Rect(s, _) { PRINT(s); }
+						_ { PRINT("other"); }
+					}
+				}
+				PRINT(shapes);`,
+			out: "12120other3other[Shape::Circle(2),Shape::Rect(3,4),Shape::Empty]"},
+		{
+			src: `
+				type State enum { Idle, Running(Int), Done }
+				func (s State) next() State {
+					match s {
+						State::Idle { return State::Running(0); }
+						State::Running(n) {
+							if n == 2 {
+								return State::Done;
+							}
+							return State::Running(n + 1);
+						}
+						State::Done { return s; }
+					}
+				}
+				let s = State::Idle;
+				while s != State::Done {
+					let label = match s {
+						State::Running(n) => "running " + "!",
+						_ => "waiting",
+					};
+					PRINT(label);
+					s = s.next();
+				}
+				PRINT(s == State::Done and State::Running(1) != State::Running(2));
+				let seen = {State::Idle: 1};
+				seen[State::Running(3)] = 2;
+				PRINT(seen[State::Running(3)]);`,
+			out: "waitingrunning!running!running!true2"},
+		{
+			src: `
+				func divmod(a Int, b Int) (Int, Int) {
+					return (a / b, a % b);
+				}
+				func stats(xs List[Int]) (Int, Int, String) {
+					let lo = xs[0];
+					let hi = xs[0];
+					for x in xs {
+						if x < lo {
+							lo = x;
+						}
+						if x > hi {
+							hi = x;
+						}
+					}
+					return (lo, hi, "ok");
+				}
+				let (q, r) = divmod(17, 5);
+				PRINT(q);
+				PRINT(r);
+				let (lo, _, msg) = stats([4, 1, 9]);
+				PRINT(lo);
+				PRINT(msg);
+				let pair = (q, ("a", [1.5]));
+				PRINT(pair);
+				PRINT((q, "a") == (3, "a") and (1, 2) != (1, 2));
+				let seen = {(0, 0): "start"};
+				seen[(1, 2)] = "end";
+				PRINT(seen[(1, 2)]);`,
+			out: "321ok(3,(a,[1.5]))falseend"},
+		{
+			src: `
+				func find(xs List[String], x String) Int? {
+					for i in 0..LEN(xs) {
+						if xs[i] == x {
+							return i;
+						}
+					}
+					return none;
+				}
+				let names = ["ann", "bob"];
+				for name in ["bob", "cy"] {
+					if let i = find(names, name) {
+						PRINT(i);
+					} else {
+						PRINT("missing");
+					}
+				}
+				let ages Map[String, Int?] = {"ann": 30, "bob": none};
+				PRINT(ages);
+				let age = ages["bob"];
+				if age == none {
+					age = 40;
+				}
+				if let age = age {
+					PRINT(age + 1);
+				}
+				PRINT(find(names, "ann") == 0);`,
+			out: "1missing{ann:30,bob:none}41true"},
+		{
+			src: `
+				type UserId = Int;
+				type Meters Int;
+				type Ids List[UserId];
+				func (m Meters) twice() Meters {
+					return m + m;
+				}
+				func total(ids Ids) UserId {
+					let sum = 0;
+					for id in ids {
+						sum = sum + id;
+					}
+					return sum;
+				}
+				let ids = Ids([1, 2]);
+				ids.APPEND(3);
+				PRINT(total(ids));
+				let m = Meters(4).twice();
+				PRINT(m);
+				PRINT(Int(m) + 1);
+				PRINT(m == Meters(8));
+				let names Map[Meters, String] = {m: "eight"};
+				PRINT(names[Meters(8)]);`,
+			out: "689trueeight"},
+		{
+			src: `
+				type Point struct { x Int, y Int }
+				type Named interface { name() String }
+				type Shape interface {
+					name() String,
+					area() Int,
+				}
+				type Rect struct { w Int, h Int }
+				func (r Rect) name() String {
+					return "rect";
+				}
+				func (r Rect) area() Int {
+					return r.w * r.h;
+				}
+				func (p Point) name() String {
+					return "point";
+				}
+				func (p Point) area() Int {
+					return 0;
+				}
+				func describe(s Shape) Int {
+					PRINT(s.name());
+					return s.area();
+				}
+				func biggest(shapes List[Shape]) Shape? {
+					let best Shape? = none;
+					let most = -1;
+					for s in shapes {
+						if s.area() > most {
+							best = s;
+							most = s.area();
+						}
+					}
+					return best;
+				}
+				let shapes List[Shape] = [Rect(2, 3), Point(1, 2)];
+				shapes.APPEND(Rect(4, 4));
+				for s in shapes {
+					PRINT(describe(s) + 1);
+				}
+				PRINT(shapes[1]);
+				let n Named = shapes[0];
+				PRINT(n.name());
+				PRINT(biggest(shapes));
+				let first Shape? = Rect(1, 1);
+				PRINT(first);`,
+			out: "rect7point1rect17Point(x:1,y:2)rectRect(w:4,h:4)Rect(w:1,h:1)"},
+		{
+			src: `
+				type Ordered interface { GT(other Ordered) Bool }
+				type Equal interface { EQ(other Equal) Bool }
+				func max[T Ordered](a T, b T) T {
+					if a > b {
+						return a;
+					}
+					return b;
+				}
+				func largest[T Ordered](xs List[T]) T? {
+					let best T? = none;
+					for x in xs {
+						if let b = best {
+							best = max(b, x);
+						} else {
+							best = x;
+						}
+					}
+					return best;
+				}
+				func swap[A, B](p (A, B)) (B, A) {
+					let (a, b) = p;
+					return (b, a);
+				}
+				func count[T Equal](xs List[T], x T) Int {
+					let n = 0;
+					for y in xs {
+						if y == x {
+							n = n + 1;
+						}
+					}
+					return n;
+				}
+				func twice[T](x T) List[T] {
+					return [x, x];
+				}
+				PRINT(max(3, 7));
+				PRINT(max("pear", "apple"));
+				PRINT(largest([4, 9, 2]));
+				PRINT(largest([2.5]));
+				PRINT(swap((1, "one")));
+				PRINT(count(["a", "b", "a"], "a"));
+				PRINT(count(twice(true), true));
+				PRINT(twice([1]));`,
+			out: "7pear92.5(one,1)22[[1],[1]]"},
+		{
+			src: `
+				type Button struct { label String, onClick func(String) String }
+				func inc(x Int) Int {
+					return x + 1;
+				}
+				func times2(x Int) Int {
+					return x * 2;
+				}
+				func compose(f func(Int) Int, g func(Int) Int, x Int) Int {
+					return g(f(x));
+				}
+				func pick(big Bool) func(Int) Int {
+					if big {
+						return times2;
+					}
+					return inc;
+				}
+				func each[T](xs List[T], f func(T) Nothing) Nothing {
+					for x in xs {
+						f(x);
+					}
+					return PRINT("done");
+				}
+				func say(s String) Nothing {
+					return PRINT(s);
+				}
+				func shout(s String) String {
+					return s + "!";
+				}
+				PRINT(compose(inc, times2, 3));
+				PRINT(pick(true)(5));
+				let fs = [inc, times2, pick(false)];
+				let total = 0;
+				for f in fs {
+					total = total + f(10);
+				}
+				PRINT(total);
+				each(["a", "b"], say);
+				let b = Button("ok", shout);
+				PRINT(b.onClick(b.label));
+				PRINT(b);`,
+			out: "81042abdoneok!Button(label:ok,onClick:<func>)"},
+		{
+			src: `
+				type Point struct { x Int, y Int }
+				func (p Point) applyX(f func(Int) Int) Int {
+					return f(p.x);
+				}
+				PRINT(Point(4, 5).applyX(func(x Int) Int { return x * 3; }));`,
+			out: "12"},
+		{
+			src: `
+				type Counter struct { n Int }
+				func inc(x Int) Int {
+					return x + 1;
+				}
+				func apply(f func(Int) Int, x Int) Int {
+					return f(x);
+				}
+				func (c Counter) inc() Counter {
+					return Counter(apply(inc, c.n));
+				}
+				PRINT(Counter(1).inc().inc());`,
+			out: "Counter(n:3)"},
+		{
+			src: `
+				type A struct { n Int }
+				type B struct { n Int }
+				type Shade enum { Light, Dark(B) }
+				func (a A) toB() B {
+					return B(a.n * 2);
+				}
+				func (a A) shade() Shade {
+					return Shade::Dark(a.toB());
+				}
+				PRINT(A(2).toB());
+				PRINT(A(3).shade());`,
+			out: "B(n:4)Shade::Dark(B(n:6))"},
+		{
+			src: `
+				type Shape enum { Circle(Int), Square(Int) }
+				type P struct { n Int }
+				func f(x Int) Int {
+					let x = 5;
+					return x;
+				}
+				func (p P) get() Int {
+					let p = 9;
+					return p;
+				}
+				PRINT(f(1));
+				PRINT(P(1).get());
+				for i in 0..2 {
+					let i = 7;
+					PRINT(i);
+				}
+				for x in [1, 2] {
+					let x = "x";
+					PRINT(x);
+				}
+				match Shape::Circle(3) {
+					Shape::Circle(r) {
+						let r = r * 2;
+						PRINT(r);
+					}
+					Shape::Square(s) { PRINT(s); }
+				}
+				let found Int? = 4;
+				if let v = found {
+					let v = v + 1;
+					PRINT(v);
+				}
+				let g = func(y Int) Int {
+					let y = y * 10;
+					return y;
+				};
+				PRINT(g(2));`,
+			out: "5977xx6520"},
+		{
+			src: `
+				func inc(x Int) Int {
+					return x + 1;
+				}
+				func apply(f func(Int) Int, x Int) Int {
+					return f(x);
+				}
+				func max(a Int, b Int) Int {
+					if a > b {
+						return a;
+					}
+					return b;
+				}
+				let g = apply;
+				PRINT(apply(inc, 2));
+				PRINT(g(inc, 5));
+				PRINT(max(3, 9));`,
+			out: "369"},
+		{
+			src: `
+				type Point struct { x Int, y Int }
+				func adder(n Int) func(Int) Int {
+					return func(x Int) Int { return x + n; };
+				}
+				func (p Point) shifter() func(Int) Point {
+					return func(d Int) Point { return Point(p.x + d, p.y + d); };
+				}
+				func apply[T](xs List[T], f func(T) T) List[T] {
+					let out List[T] = [];
+					for x in xs {
+						out.APPEND(f(x));
+					}
+					return out;
+				}
+				let add5 = adder(5);
+				PRINT(add5(1));
+				let scale = 3;
+				let fs = [add5, func(x Int) Int { return x * scale; }];
+				scale = 100;
+				for f in fs {
+					PRINT(f(2));
+				}
+				PRINT(apply([1, 2, 3], func(x Int) Int { return x * x; }));
+				let prefix = "hi ";
+				PRINT(apply(["a", "b"], func(s String) String { return prefix + s; }));
+				PRINT(Point(1, 2).shifter()(10));
+				let outer = func(x Int) func(Int) Int {
+					return func(y Int) Int { return x * 10 + y + scale; };
+				};
+				PRINT(outer(1)(2));
+				PRINT(func() String { return "now"; }());`,
+			out: "676[1,4,9][hia,hib]Point(x:11,y:12)112now"},
+		{
+			src: `
+				type Counter struct { n Int }
+				func (c Counter) report() {
+					PRINT(c.n);
+				}
+				func positives(xs List[Int]) {
+					for x in xs {
+						if x < 0 {
+							return;
+						}
+						PRINT(x);
+					}
+				}
+				func each[T](xs List[T], f func(T) Nothing) {
+					for x in xs {
+						f(x);
+					}
+				}
+				positives([1, 2, -1, 3]);
+				Counter(7).report();
+				let seen = func(s String) {
+					PRINT(s + "?");
+				};
+				each(["a", "b"], seen);
+				let r Nothing = seen("c");`,
+			out: "127a?b?c?"},
+		{
+			src: `
+				type Span struct { lo Int, hi Int }
+				func run() {
+					PRINT(fact(5));
+					PRINT(isEven(10));
+					PRINT(isOdd(7));
+					PRINT(Span(1, 4).sum());
+					PRINT(firsts(["a", "b", "c"], 2));
+				}
+				func fact(n Int) Int {
+					if n <= 1 {
+						return 1;
+					}
+					return n * fact(n - 1);
+				}
+				func isEven(n Int) Bool {
+					if n == 0 {
+						return true;
+					}
+					return isOdd(n - 1);
+				}
+				func isOdd(n Int) Bool {
+					if n == 0 {
+						return false;
+					}
+					return isEven(n - 1);
+				}
+				func (s Span) sum() Int {
+					if s.lo > s.hi {
+						return 0;
+					}
+					return s.lo + Span(s.lo + 1, s.hi).sum();
+				}
+				func firsts[T](xs List[T], n Int) List[T] {
+					if n == 0 {
+						let empty List[T] = [];
+						return empty;
+					}
+					let rest = firsts(xs, n - 1);
+					rest.APPEND(xs[n - 1]);
+					return rest;
+				}
+				run();`,
+			out: "120truetrue10[a,b]"}}
 
 	for i, test := range tests {
 		program := Parse(test.src)
@@ -188,3 +1119,41 @@ func TestOutPut(t *testing.T) {
 		}
 	}
 }
+
+func TestRuntimeError(t *testing.T) {
+	tests := []struct {
+		src string
+		err string
+	}{
+		{`PRINT(1 / 0);`, "integer division by zero"},
+		{`let x = 0;
+		PRINT(5 % x);`, "integer modulo by zero"},
+		{`let xs = [1, 2, 3];
+		PRINT(xs[3]);`, "index 3 out of range for List of length 3"},
+		{`let xs = [1];
+		xs[-1] = 2;`, "index -1 out of range for List of length 1"},
+		{`let m = {"a": 1};
+		PRINT(m["b"]);`, "key b not in Map"},
+		{`PRINT(INT(1e20));`, "cannot convert 1e+20 to Int"},
+		{`PRINT(INT(-3e9));`, "cannot convert -3000000000.0 to Int"},
+		{`let s = 0;
+		for i in 0..3 step s {
+			PRINT(i);
+		}`, "range step cannot be zero"}}
+
+	for i, test := range tests {
+		program := Parse(test.src)
+		TypeCheck(program)
+		code := gen.GenWrapper(program)
+
+		func() {
+			defer func() {
+				expected := "exit status 1: runtime error: " + test.err
+				if r := recover(); r != expected {
+					t.Fatalf("test [%d] wanted '%s', got='%v'", i, expected, r)
+				}
+			}()
+			Compile(code)
+		}()
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 )
 
 func check(err error) {
@@ -48,13 +49,15 @@ func Compile(code bytes.Buffer) string {
 		panic(fmt.Sprintf("error: %s", out.String()))
 	}
 
-	cmd := exec.Command("./main.exe")
-	var outb bytes.Buffer
+	cmd := exec.Command("./main")
+	var outb, errb bytes.Buffer
 	cmd.Stdout = &outb
+	cmd.Stderr = &errb
 	err = cmd.Run()
 
+	// runtime errors exit with their message on stderr
 	if err != nil {
-		panic(err.Error())
+		panic(fmt.Sprintf("%s: %s", err.Error(), strings.TrimSpace(errb.String())))
 	}
 
 	return outb.String()
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"fmt"
+	"github.com/Lebonesco/go-compiler/ast"
 	"testing"
 )
 
@@ -28,3 +30,100 @@ func TestParser(t *testing.T) {
 
 	Parse(input)
 }
+
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		src string
+		out string
+	}{
+		{`1 + 2 * 3;`, `(1 + (2 * 3))`},
+		{`1 * 2 + 3;`, `((1 * 2) + 3)`},
+		{`1 - 2 - 3;`, `((1 - 2) - 3)`},
+		{`8 / 4 / 2;`, `((8 / 4) / 2)`},
+		{`(1 + 2) * 3;`, `((1 + 2) * 3)`},
+		{`x < y + 1;`, `(x < (y + 1))`},
+		{`a + 1 < b and c;`, `(((a + 1) < b) and c)`},
+		{`a or b and c;`, `(a or (b and c))`},
+		{`a and b or c and d;`, `((a and b) or (c and d))`},
+		{`a == b < c;`, `(a == (b < c))`},
+		{`a < b == c >= d;`, `((a < b) == (c >= d))`},
+		{`a != b or x <= y * 2;`, `((a != b) or (x <= (y * 2)))`},
+		{`f(1 + 2) * 3;`, `(f((1 + 2)) * 3)`},
+		{`-a * b;`, `((- a) * b)`},
+		{`a - -b;`, `(a - (- b))`},
+		{`- -1;`, `(- (- 1))`},
+		{`not a and b;`, `((not a) and b)`},
+		{`not not a or -x < y;`, `((not (not a)) or ((- x) < y))`}}
+
+	for i, test := range tests {
+		program := Parse(test.src)
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("test [%d] expected ExpressionStatement. got=%T", i, program.Statements[0])
+		}
+
+		if got := exprString(stmt.Expression); got != test.out {
+			t.Fatalf("test [%d] wrong tree. expected='%s', got='%s'", i, test.out, got)
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		src       string
+		start     string
+		end       string
+		inclusive bool
+	}{
+		{`for i in 0..10 {}`, `0`, `10`, false},
+		{`for i in 1..=n {}`, `1`, `n`, true},
+		{`for i in n+1..5 {}`, `(n + 1)`, `5`, false},
+		{`for i in -1..3 {}`, `(- 1)`, `3`, false},
+		{`for i in n-1..=3 {}`, `(n - 1)`, `3`, true},
+		{`for i in a..b*2 {}`, `a`, `(b * 2)`, false},
+		{`for i in 0..1.5 {}`, `0`, `1.5`, false}}
+
+	for i, test := range tests {
+		program := Parse(test.src)
+		stmt, ok := program.Statements[0].(*ast.ForRangeStatement)
+		if !ok {
+			t.Fatalf("test [%d] expected ForRangeStatement. got=%T", i, program.Statements[0])
+		}
+
+		start, end := exprString(stmt.Start), exprString(stmt.End)
+		if start != test.start || end != test.end || stmt.Inclusive != test.inclusive {
+			t.Fatalf("test [%d] wrong range. expected='%s..%s' (inclusive %t), got='%s..%s' (inclusive %t)",
+				i, test.start, test.end, test.inclusive, start, end, stmt.Inclusive)
+		}
+	}
+}
+
+// fully parenthesize an expression tree
+func exprString(node ast.Expression) string {
+	switch node := node.(type) {
+	case *ast.InfixExpression:
+		return fmt.Sprintf("(%s %s %s)", exprString(node.Left), node.Operator, exprString(node.Right))
+	case *ast.PrefixExpression:
+		return fmt.Sprintf("(%s %s)", node.Operator, exprString(node.Right))
+	case *ast.FunctionCall:
+		args := ""
+		for i, arg := range node.Args {
+			if i != 0 {
+				args += ", "
+			}
+			args += exprString(arg)
+		}
+		return fmt.Sprintf("%s(%s)", node.Name, args)
+	case *ast.IntegerLiteral:
+		return node.Value
+	case *ast.FloatLiteral:
+		return node.Value
+	case *ast.Identifier:
+		return node.Value
+	case *ast.StringLiteral:
+		return node.Value
+	case *ast.Boolean:
+		return fmt.Sprint(node.Value)
+	}
+	return fmt.Sprintf("%T", node)
+}
@@ -119,3 +119,40 @@ func TestToken(t *testing.T) {
 		}
 	}
 }
+
+func TestNumberToken(t *testing.T) {
+	tests := []struct {
+		expectedType    token.Type
+		expectedLiteral string
+	}{
+		{token.TokMap.Type("int"), "1"},
+		{token.TokMap.Type("fraction"), ".5"},
+		{token.TokMap.Type("float"), "2e3"},
+		{token.TokMap.Type("int"), "2"},
+		{token.TokMap.Type("fraction"), ".5E-3"},
+		{token.TokMap.Type("int"), "0"},
+		{token.TokMap.Type("range"), ".."},
+		{token.TokMap.Type("int"), "10"},
+		{token.TokMap.Type("int"), "1"},
+		{token.TokMap.Type("rangeinc"), "..="},
+		{token.TokMap.Type("ident"), "n"},
+		{token.TokMap.Type("ident"), "a"},
+		{token.TokMap.Type("range"), ".."},
+		{token.TokMap.Type("ident"), "b"},
+	}
+
+	l := lexer.NewLexer([]byte(`1.5 2e3 2.5E-3 0..10 1..=n a..b`))
+	for i, tt := range tests {
+		tok := l.Scan()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected='%s', got='%s'",
+				i, token.TokMap.Id(tt.expectedType), token.TokMap.Id(tok.Type))
+		}
+
+		if string(tok.Lit) != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - literal wrong. expected='%q', got='%q'",
+				i, tt.expectedLiteral, string(tok.Lit))
+		}
+	}
+}